@@ -2,106 +2,71 @@ package main
 
 import (
 	"fmt"
-	"log"
-	"time"
-
-	amqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/bootdotdev/learn-pub-sub-starter/internal/gamelogic"
 	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
 	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
 )
 
-func handlerPause(gs *gamelogic.GameState) func(routing.PlayingState) pubsub.AckType {
-	return func(ps routing.PlayingState) pubsub.AckType {
+func handlerPause(gs *gamelogic.GameState) func(routing.PlayingState, pubsub.Delivery) pubsub.AckType {
+	return func(ps routing.PlayingState, _ pubsub.Delivery) pubsub.AckType {
 		defer fmt.Print("> ")
 		gs.HandlePause(ps)
 		return pubsub.Ack
 	}
 }
 
-func handlerMove(
-	gs *gamelogic.GameState,
-	publishCh *amqp.Channel,
-) func(gamelogic.ArmyMove) pubsub.AckType {
-	return func(move gamelogic.ArmyMove) pubsub.AckType {
+func handlerScenario(gs *gamelogic.GameState) func(gamelogic.Scenario, pubsub.Delivery) pubsub.AckType {
+	return func(scenario gamelogic.Scenario, _ pubsub.Delivery) pubsub.AckType {
 		defer fmt.Print("> ")
-
-		moveOutcome := gs.HandleMove(move)
-		switch moveOutcome {
-		case gamelogic.MoveOutcomeSamePlayer:
-			return pubsub.Ack
-		case gamelogic.MoveOutComeSafe:
-			return pubsub.Ack
-		case gamelogic.MoveOutcomeMakeWar:
-			err := pubsub.PublishJSON(
-				publishCh,
-				routing.ExchangePerilTopic,
-				routing.WarRecognitionsPrefix+"."+gs.GetUsername(),
-				gamelogic.RecognitionOfWar{
-					Attacker: move.Player,
-					Defender: gs.GetPlayerSnap(),
-				},
-			)
-			if err != nil {
-				fmt.Printf("error: %s\n", err)
-				return pubsub.NackRequeue
-			}
-			return pubsub.Ack
+		err := scenario.Validate()
+		if err != nil {
+			fmt.Printf("error: the server sent a scenario we can't play: %s\n", err)
+			return pubsub.NackDiscard
 		}
-
-		fmt.Println("error: unknown move outcome")
-		return pubsub.NackDiscard
+		gs.HandleScenario(&scenario)
+		return pubsub.Ack
 	}
 }
 
-func handlerWar(
-	gs *gamelogic.GameState,
-	publishCh *amqp.Channel,
-) func(dw gamelogic.RecognitionOfWar) pubsub.AckType {
-	return func(dw gamelogic.RecognitionOfWar) pubsub.AckType {
+func handlerRoster(gs *gamelogic.GameState) func(gamelogic.Roster, pubsub.Delivery) pubsub.AckType {
+	return func(r gamelogic.Roster, _ pubsub.Delivery) pubsub.AckType {
 		defer fmt.Print("> ")
-		warOutcome, winner, loser := gs.HandleWar(dw)
-
-		var msg string
+		gs.HandleRoster(r)
+		return pubsub.Ack
+	}
+}
 
-		switch warOutcome {
-		case gamelogic.WarOutcomeNotInvolved:
-			return pubsub.NackRequeue
-		case gamelogic.WarOutcomeNoUnits:
-			return pubsub.NackDiscard
-		case gamelogic.WarOutcomeOpponentWon:
-			fallthrough
-		case gamelogic.WarOutcomeYouWon:
-			msg = fmt.Sprintf("%s won against %s", winner, loser)
-		case gamelogic.WarOutcomeDraw:
-			msg = fmt.Sprintf(
-				"A war between %s and %s resulted in a draw.",
-				winner,
-				loser,
-			)
-		default:
-			fmt.Println("error: unknown war outcome")
-			return pubsub.NackDiscard
-		}
+func handlerMoveRejection(gs *gamelogic.GameState) func(gamelogic.MoveRejection, pubsub.Delivery) pubsub.AckType {
+	return func(mr gamelogic.MoveRejection, _ pubsub.Delivery) pubsub.AckType {
+		defer fmt.Print("> ")
+		gs.HandleMoveRejection(mr)
+		return pubsub.Ack
+	}
+}
 
-		gl := routing.GameLog{
-			CurrentTime: time.Now().UTC(),
-			Message:     msg,
-			Username:    winner,
-		}
+func handlerMove(gs *gamelogic.GameState) func(gamelogic.ArmyMove, pubsub.Delivery) pubsub.AckType {
+	return func(move gamelogic.ArmyMove, _ pubsub.Delivery) pubsub.AckType {
+		defer fmt.Print("> ")
+		gs.HandleMove(move)
+		return pubsub.Ack
+	}
+}
 
-		err := pubsub.PublishGob(
-			publishCh,
-			routing.ExchangePerilTopic,
-			fmt.Sprintf("%s.%s", routing.GameLogSlug, gs.GetUsername()),
-			gl,
-		)
-		if err != nil {
-			log.Println("could not publish gob")
-			return pubsub.NackRequeue
+func handlerTick(gs *gamelogic.GameState) func(gamelogic.Tick, pubsub.Delivery) pubsub.AckType {
+	return func(tick gamelogic.Tick, _ pubsub.Delivery) pubsub.AckType {
+		if gs.HandleTick(tick) {
+			fmt.Print("> ")
 		}
+		return pubsub.Ack
+	}
+}
 
+// handlerWar shows the player a war the server has fought them in.
+func handlerWar(gs *gamelogic.GameState) func(gamelogic.RecognitionOfWar, pubsub.Delivery) pubsub.AckType {
+	return func(rw gamelogic.RecognitionOfWar, _ pubsub.Delivery) pubsub.AckType {
+		defer fmt.Print("> ")
+		gs.HandleWar(rw)
 		return pubsub.Ack
 	}
 }
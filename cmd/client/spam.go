@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/gamelogic"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
+)
+
+func commandSpam(publisher *pubsub.Publisher, username string, words []string) error {
+	if len(words) < 2 {
+		return errors.New("usage: spam <n>")
+	}
+	n, err := strconv.Atoi(words[1])
+	if err != nil || n < 1 {
+		return fmt.Errorf("error: %s is not a valid number", words[1])
+	}
+
+	for i := 0; i < n; i++ {
+		err := pubsub.Publish(
+			publisher,
+			pubsub.ContentTypeGob,
+			routing.ExchangePerilTopic,
+			routing.GameLogSlug+"."+username,
+			routing.GameLog{
+				CurrentTime: time.Now().UTC(),
+				Message:     gamelogic.GetMaliciousLog(),
+				Username:    username,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("error: published %v of %v logs: %w", i, n, err)
+		}
+	}
+	fmt.Printf("Published %v malicious logs\n", n)
+	return nil
+}
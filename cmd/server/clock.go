@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/gamelogic"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
+)
+
+// runClock advances the world every interval, and whenever something is sent
+// on manual, until ctx is done. An interval of 0 plays turn by turn: the
+// world only advances when told to. Ticks are published on events, which
+// records them; war reports go out on publisher.
+func runClock(
+	ctx context.Context,
+	world *gamelogic.World,
+	events pubsub.Channel,
+	publisher pubsub.Channel,
+	interval time.Duration,
+	manual <-chan struct{},
+) {
+	var timer <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		timer = ticker.C
+	}
+
+	var number int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer:
+		case <-manual:
+		}
+
+		number++
+		advance(world, events, publisher, number)
+	}
+}
+
+// advance resolves one tick: it applies the orders queued since the last
+// one, tells players about their moves, publishes where every unit is now
+// and reports the wars the tick fought.
+func advance(world *gamelogic.World, events, publisher pubsub.Channel, number int64) {
+	result := world.Tick(number)
+	publishMoveResults(events, result.Moves)
+
+	err := pubsub.Publish(
+		events,
+		pubsub.ContentTypeProtobuf,
+		routing.ExchangePerilDirect,
+		routing.TickKey,
+		result.Tick,
+	)
+	if err != nil {
+		log.Printf("could not publish tick %v: %v", number, err)
+	}
+
+	for i, rw := range result.Wars {
+		publishWar(publisher, rw, result.Results[i])
+	}
+}
+
+// publishWar tells both sides about a war the world has fought, so they can
+// see how it went, and logs it. The war's casualties are in the tick.
+func publishWar(publisher pubsub.Channel, rw gamelogic.RecognitionOfWar, war gamelogic.War) {
+	for _, username := range []string{rw.Attacker.Username, rw.Defender.Username} {
+		err := pubsub.Publish(
+			publisher,
+			pubsub.ContentTypeProtobuf,
+			routing.ExchangePerilTopic,
+			routing.WarRecognitionsPrefix+"."+username,
+			rw,
+		)
+		if err != nil {
+			log.Printf("could not tell %s about the war between %s and %s: %v",
+				username, rw.Attacker.Username, rw.Defender.Username, err)
+		}
+	}
+
+	gl := war.GameLog(time.Now().UTC())
+	err := pubsub.Publish(
+		publisher,
+		pubsub.ContentTypeProtobuf,
+		routing.ExchangePerilTopic,
+		routing.GameLogSlug+"."+gl.Username,
+		gl,
+	)
+	if err != nil {
+		log.Printf("could not publish game log: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
+)
+
+const defaultDeadLetterListLimit = 20
+
+func commandDeadLetters(conn *pubsub.Conn, words []string) error {
+	if len(words) < 2 {
+		return errors.New("usage: dlq list [n] | dlq replay <position> <position>...")
+	}
+
+	switch words[1] {
+	case "list":
+		limit := defaultDeadLetterListLimit
+		if len(words) > 2 {
+			n, err := strconv.Atoi(words[2])
+			if err != nil || n < 1 {
+				return fmt.Errorf("error: %s is not a valid number", words[2])
+			}
+			limit = n
+		}
+
+		deadLetters, err := pubsub.ListDeadLetters(conn, limit)
+		if err != nil {
+			return fmt.Errorf("could not list dead letters: %w", err)
+		}
+		if len(deadLetters) == 0 {
+			fmt.Println("The dead letter queue is empty.")
+			return nil
+		}
+		for _, dl := range deadLetters {
+			printDeadLetter(dl)
+		}
+		return nil
+	case "replay":
+		if len(words) < 3 {
+			return errors.New("usage: dlq replay <position> <position>...")
+		}
+		positions := []int{}
+		for _, word := range words[2:] {
+			position, err := strconv.Atoi(word)
+			if err != nil {
+				return fmt.Errorf("error: %s is not a valid position", word)
+			}
+			positions = append(positions, position)
+		}
+
+		replayed, err := pubsub.ReplayDeadLetters(conn, positions...)
+		for _, dl := range replayed {
+			fmt.Printf(
+				"Replayed dead letter %v to %s with key %s\n",
+				dl.Position, dl.OriginalExchange, dl.OriginalRoutingKey,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("could not replay dead letters: %w", err)
+		}
+		if len(replayed) < len(positions) {
+			fmt.Println("Some positions were past the end of the dead letter queue.")
+		}
+		return nil
+	default:
+		return fmt.Errorf("error: unknown dlq command %s", words[1])
+	}
+}
+
+func printDeadLetter(dl pubsub.DeadLetter) {
+	fmt.Printf(
+		"%v: %s %s -> %s (%s, %v bytes)\n",
+		dl.Position,
+		dl.MessageID,
+		dl.OriginalExchange,
+		dl.OriginalRoutingKey,
+		dl.ContentType,
+		len(dl.Body),
+	)
+	if dl.Error != "" {
+		fmt.Printf("    error: %s\n", dl.Error)
+	}
+	if dl.ContentType == string(pubsub.ContentTypeJSON) {
+		fmt.Printf("    body: %s\n", strings.TrimSpace(string(dl.Body)))
+	}
+	for _, death := range dl.Deaths {
+		fmt.Printf(
+			"    x-death: queue=%s reason=%s count=%v time=%s keys=%s\n",
+			death.Queue,
+			death.Reason,
+			death.Count,
+			death.Time.Format(time.RFC3339),
+			strings.Join(death.RoutingKeys, ","),
+		)
+	}
+}
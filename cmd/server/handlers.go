@@ -1,16 +1,18 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/bootdotdev/learn-pub-sub-starter/internal/gamelogic"
 	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
 	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
 )
 
-func handlerLog() func(routing.GameLog) pubsub.AckType {
-	return func(gamelog routing.GameLog) pubsub.AckType {
+func handlerLog() func(routing.GameLog, pubsub.Delivery) pubsub.AckType {
+	return func(gamelog routing.GameLog, _ pubsub.Delivery) pubsub.AckType {
 		defer fmt.Print("> ")
 		err := gamelogic.WriteLog(gamelog)
 		if err != nil {
@@ -20,3 +22,120 @@ func handlerLog() func(routing.GameLog) pubsub.AckType {
 		return pubsub.Ack
 	}
 }
+
+// handlerScenarioRequest answers a client asking which scenario is being
+// played by broadcasting it to everyone. It also tells the client which
+// units the world says it has, as the ones it saved may have died since.
+func handlerScenarioRequest(
+	world *gamelogic.World,
+	publisher *pubsub.Publisher,
+) func(routing.ScenarioRequest, pubsub.Delivery) pubsub.AckType {
+	return func(req routing.ScenarioRequest, _ pubsub.Delivery) pubsub.AckType {
+		err := publishScenario(publisher, world.Scenario())
+		if err != nil {
+			log.Printf("could not send scenario to %s: %v", req.Username, err)
+			return pubsub.NackRequeue
+		}
+		err = pubsub.Publish(
+			publisher,
+			pubsub.ContentTypeProtobuf,
+			routing.ExchangePerilTopic,
+			routing.RosterPrefix+"."+req.Username,
+			world.Roster(req.Username),
+		)
+		if err != nil {
+			log.Printf("could not send roster to %s: %v", req.Username, err)
+			return pubsub.NackRequeue
+		}
+		return pubsub.Ack
+	}
+}
+
+func publishScenario(publisher *pubsub.Publisher, scenario *gamelogic.Scenario) error {
+	return pubsub.Publish(
+		publisher,
+		pubsub.ContentTypeJSON,
+		routing.ExchangePerilDirect,
+		routing.ScenarioKey,
+		scenario,
+	)
+}
+
+// handlerSpawn queues spawns for the next tick, which tells the player about
+// any it refuses.
+func handlerSpawn(world *gamelogic.World) func(gamelogic.UnitSpawned, pubsub.Delivery) pubsub.AckType {
+	return func(us gamelogic.UnitSpawned, delivery pubsub.Delivery) pubsub.AckType {
+		// As with moves, the routing key says who sent the spawn.
+		sender := strings.TrimPrefix(delivery.RoutingKey, routing.SpawnsPrefix+".")
+		world.QueueSpawn(sender, us)
+		return pubsub.Ack
+	}
+}
+
+// handlerWorldMove queues moves for the next tick; publishMoveResults tells
+// the players what became of them once it has been resolved.
+func handlerWorldMove(world *gamelogic.World) func(gamelogic.ArmyMove, pubsub.Delivery) pubsub.AckType {
+	return func(move gamelogic.ArmyMove, delivery pubsub.Delivery) pubsub.AckType {
+		// The routing key, not the payload, says who sent the move.
+		sender := strings.TrimPrefix(delivery.RoutingKey, routing.ArmyMovesPrefix+".")
+		world.QueueMove(sender, move, delivery.MessageID)
+		return pubsub.Ack
+	}
+}
+
+func publishMoveResults(publisher pubsub.Channel, results []gamelogic.MoveResult) {
+	for _, result := range results {
+		var invalid *gamelogic.ValidationError
+		if errors.As(result.Err, &invalid) {
+			log.Printf("rejected move from %s: %v", result.Sender, result.Err)
+			publishRejection(publisher, result, invalid)
+			continue
+		}
+		if result.Err != nil {
+			log.Printf("could not apply move from %s: %v", result.Sender, result.Err)
+			continue
+		}
+
+		err := pubsub.Publish(
+			publisher,
+			pubsub.ContentTypeProtobuf,
+			routing.ExchangePerilTopic,
+			routing.AcceptedMovesPrefix+"."+result.Move.Player.Username,
+			result.Move,
+			pubsub.WithCorrelationID(result.CorrelationID),
+			pubsub.WithSchemaVersion(gamelogic.ArmyMoveSchemaVersion),
+		)
+		if err != nil {
+			log.Printf("could not publish accepted move: %v", err)
+		}
+	}
+}
+
+func publishRejection(
+	publisher pubsub.Channel,
+	result gamelogic.MoveResult,
+	invalid *gamelogic.ValidationError,
+) {
+	unitIDs := []int{}
+	for _, unit := range result.Move.Units {
+		unitIDs = append(unitIDs, unit.ID)
+	}
+
+	err := pubsub.Publish(
+		publisher,
+		pubsub.ContentTypeProtobuf,
+		routing.ExchangePerilTopic,
+		routing.MoveRejectionsPrefix+"."+result.Sender,
+		gamelogic.MoveRejection{
+			Username:   result.Sender,
+			Reason:     invalid.Reason,
+			Detail:     invalid.Detail,
+			ToLocation: result.Move.ToLocation,
+			UnitIDs:    unitIDs,
+		},
+		pubsub.WithCorrelationID(result.CorrelationID),
+	)
+	if err != nil {
+		log.Printf("could not publish move rejection: %v", err)
+	}
+}
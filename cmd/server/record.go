@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/eventstore"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
+)
+
+// recordingChannel records everything published through it in the event log
+// once the broker has taken it. The authority publishes its ticks and
+// pauses through one, so the log holds them in the order they happened.
+type recordingChannel struct {
+	ch    pubsub.Channel
+	store *eventstore.Store
+}
+
+func newRecordingChannel(ch pubsub.Channel, store *eventstore.Store) *recordingChannel {
+	return &recordingChannel{
+		ch:    ch,
+		store: store,
+	}
+}
+
+func (r *recordingChannel) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	err := r.ch.Publish(exchange, key, mandatory, immediate, msg)
+	if err != nil {
+		return err
+	}
+
+	version, ok := msg.Headers[pubsub.HeaderSchemaVersion].(int32)
+	if !ok {
+		version = pubsub.DefaultSchemaVersion
+	}
+	_, err = r.store.Append(eventstore.Event{
+		ReceivedAt:    time.Now().UTC(),
+		PublishedAt:   msg.Timestamp,
+		MessageID:     msg.MessageId,
+		CorrelationID: msg.CorrelationId,
+		Exchange:      exchange,
+		RoutingKey:    key,
+		ContentType:   msg.ContentType,
+		SchemaVersion: int(version),
+		Body:          msg.Body,
+	})
+	if err != nil {
+		// The message is out; failing the publish would only get it sent
+		// twice.
+		log.Printf("could not record event: %v", err)
+	}
+	return nil
+}
+
+// recorded records every message handler is given in store before handling
+// it. The authority records the orders it consumes this way, rather than
+// through a queue of its own: a queue bound to the order keys would make
+// them routable with no server running, and clients could no longer tell.
+func recorded[T any](
+	store *eventstore.Store,
+	handler func(T, pubsub.Delivery) pubsub.AckType,
+) func(T, pubsub.Delivery) pubsub.AckType {
+	return func(val T, delivery pubsub.Delivery) pubsub.AckType {
+		err := recordDelivery(store, val, delivery)
+		if err != nil {
+			log.Printf("could not record event: %v", err)
+			return pubsub.NackRequeue
+		}
+		return handler(val, delivery)
+	}
+}
+
+// recordDelivery encodes val again with the codec it arrived in, so the
+// recorded body decodes to what the handler saw.
+func recordDelivery[T any](store *eventstore.Store, val T, delivery pubsub.Delivery) error {
+	codec, err := pubsub.LookupCodec(delivery.ContentType)
+	if err != nil {
+		return err
+	}
+	body, err := codec.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("could not encode event: %w", err)
+	}
+	_, err = store.Append(eventstore.Event{
+		ReceivedAt:    time.Now().UTC(),
+		PublishedAt:   delivery.Timestamp,
+		MessageID:     delivery.MessageID,
+		CorrelationID: delivery.CorrelationID,
+		Exchange:      delivery.Exchange,
+		RoutingKey:    delivery.RoutingKey,
+		ContentType:   string(delivery.ContentType),
+		SchemaVersion: delivery.SchemaVersion,
+		Body:          body,
+	})
+	return err
+}
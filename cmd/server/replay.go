@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/eventstore"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/gamelogic"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
+)
+
+var errReplayDone = errors.New("replay reached the requested time")
+
+// replayWorld rebuilds the world from the event log at path, as it was at
+// until. It applies the recorded ticks and pauses to a fresh World; resolver
+// and scenario only matter for looking at the result.
+func replayWorld(
+	path string,
+	until time.Time,
+	resolver gamelogic.CombatResolver,
+	scenario *gamelogic.Scenario,
+) (*gamelogic.World, int, error) {
+	world := gamelogic.NewWorld()
+	world.SetCombatResolver(resolver)
+	world.SetScenario(scenario)
+	seen := map[string]struct{}{}
+	applied := 0
+
+	err := eventstore.Read(path, func(e eventstore.Event) error {
+		if e.ReceivedAt.After(until) {
+			return errReplayDone
+		}
+		if e.MessageID != "" {
+			if _, ok := seen[e.MessageID]; ok {
+				return nil
+			}
+			seen[e.MessageID] = struct{}{}
+		}
+
+		ok, err := applyEvent(world, e)
+		if err != nil {
+			return fmt.Errorf("could not replay event %v: %w", e.Seq, err)
+		}
+		if ok {
+			applied++
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errReplayDone) {
+		return nil, applied, err
+	}
+	return world, applied, nil
+}
+
+// restoreWorld rebuilds the world the authority had when it last stopped from
+// the event log at path, so it doesn't depend on clients to tell it which
+// units they have. A missing log is a new game.
+func restoreWorld(
+	path string,
+	resolver gamelogic.CombatResolver,
+	scenario *gamelogic.Scenario,
+) (*gamelogic.World, int, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		world := gamelogic.NewWorld()
+		world.SetCombatResolver(resolver)
+		world.SetScenario(scenario)
+		return world, 0, nil
+	}
+	return replayWorld(path, time.Now(), resolver, scenario)
+}
+
+// applyEvent applies e to world if it is an event that changes the world.
+// Spawns and moves are only orders, and wars are fought inside the tick that
+// declared them: what came of all of them is in the ticks, which put units
+// where the live server said they were and remove the ones it said died,
+// rather than resolving anything again. Only the authority's clock publishes
+// ticks, so they are recorded in the order they happened, and a replay ends
+// up where the game did.
+func applyEvent(world *gamelogic.World, e eventstore.Event) (bool, error) {
+	contentType := pubsub.ContentType(e.ContentType)
+
+	switch {
+	case e.Exchange == routing.ExchangePerilDirect && e.RoutingKey == routing.PauseKey:
+		ps, err := pubsub.Decode[routing.PlayingState](contentType, e.Body)
+		if err != nil {
+			return false, err
+		}
+		world.SetPaused(ps.IsPaused)
+	case e.Exchange == routing.ExchangePerilDirect && e.RoutingKey == routing.TickKey:
+		tick, err := pubsub.Decode[gamelogic.Tick](contentType, e.Body)
+		if err != nil {
+			return false, err
+		}
+		world.ApplyTick(tick)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func commandReplay(
+	path string,
+	resolver gamelogic.CombatResolver,
+	scenario *gamelogic.Scenario,
+	words []string,
+) error {
+	until := time.Now()
+	if len(words) > 1 {
+		t, err := time.Parse(time.RFC3339, words[1])
+		if err != nil {
+			return fmt.Errorf("error: %s is not an RFC 3339 time, e.g. 2006-01-02T15:04:05Z", words[1])
+		}
+		until = t
+	}
+
+	world, applied, err := replayWorld(path, until, resolver, scenario)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Replayed %v events up to %s.\n", applied, until.Format(time.RFC3339))
+
+	if len(words) < 3 {
+		world.CommandStatus()
+		return nil
+	}
+	gs, ok := world.GameState(words[2])
+	if !ok {
+		return fmt.Errorf("error: %s had no units at that time", words[2])
+	}
+	gs.CommandStatus()
+	return nil
+}
@@ -0,0 +1,139 @@
+// Package eventstore keeps an append-only log of every message the server
+// sees, so game history can be replayed later.
+package eventstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event is one message as it was received. Body is kept encoded, exactly as
+// it came off the wire.
+type Event struct {
+	Seq           int64
+	ReceivedAt    time.Time
+	PublishedAt   time.Time
+	MessageID     string
+	CorrelationID string
+	Exchange      string
+	RoutingKey    string
+	ContentType   string
+	SchemaVersion int
+	Body          []byte
+}
+
+type Store struct {
+	mu   sync.Mutex
+	file *os.File
+	seq  int64
+}
+
+// Open opens the log at path for appending, creating it if necessary.
+func Open(path string) (*Store, error) {
+	seq := int64(0)
+	err := Read(path, func(e Event) error {
+		seq = e.Seq
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	err = truncatePartialEvent(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open event log: %w", err)
+	}
+	return &Store{
+		file: f,
+		seq:  seq,
+	}, nil
+}
+
+// Append assigns e the next sequence number and writes it to the log.
+func (s *Store) Append(e Event) (Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.Seq = s.seq + 1
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Event{}, fmt.Errorf("could not encode event: %w", err)
+	}
+	_, err = s.file.Write(append(data, '\n'))
+	if err != nil {
+		return Event{}, fmt.Errorf("could not write event: %w", err)
+	}
+	s.seq = e.Seq
+	return e, nil
+}
+
+// truncatePartialEvent drops a last line left unfinished by a crash, so the
+// next event doesn't get glued onto it.
+func truncatePartialEvent(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read event log: %w", err)
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	err = os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+	if err != nil {
+		return fmt.Errorf("could not repair event log: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Read calls fn with every event in the log at path, oldest first. It stops
+// early if fn returns an error.
+func Read(path string, fn func(Event) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open event log: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			e := Event{}
+			jsonErr := json.Unmarshal(line, &e)
+			if jsonErr != nil {
+				return fmt.Errorf("could not decode event: %w", jsonErr)
+			}
+			fnErr := fn(e)
+			if fnErr != nil {
+				return fnErr
+			}
+		}
+		// A final line without a newline is a write that was cut short by a
+		// crash; skip it.
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read event log: %w", err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package eventstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTruncatePartialEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "empty", data: "", want: ""},
+		{name: "complete", data: "{}\n{}\n", want: "{}\n{}\n"},
+		{name: "partial last event", data: "{}\n{\"Seq\":", want: "{}\n"},
+		{name: "only a partial event", data: "{\"Seq\":", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "events.log")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := truncatePartialEvent(path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("log = %q, want %q", data, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		err := truncatePartialEvent(filepath.Join(t.TempDir(), "events.log"))
+		if err != nil {
+			t.Errorf("truncatePartialEvent() = %v, want nil", err)
+		}
+	})
+}
+
+func readSeqs(t *testing.T, path string) []int64 {
+	t.Helper()
+	seqs := []int64{}
+	err := Read(path, func(e Event) error {
+		seqs = append(seqs, e.Seq)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return seqs
+}
+
+func TestStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"spawns.alice", "moves.alice"} {
+		if _, err := store.Append(Event{RoutingKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	// Simulate a crash partway through writing a third event.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"Seq":3,"Rout`)
+	f.Close()
+
+	if seqs := readSeqs(t, path); !reflect.DeepEqual(seqs, []int64{1, 2}) {
+		t.Errorf("read %v before reopening, want the partial event skipped", seqs)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := store.Append(Event{RoutingKey: "spawns.bob"})
+	store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Seq != 3 {
+		t.Errorf("seq = %v, want 3", e.Seq)
+	}
+	if seqs := readSeqs(t, path); !reflect.DeepEqual(seqs, []int64{1, 2, 3}) {
+		t.Errorf("read %v after reopening, want 1 to 3", seqs)
+	}
+}
+
+func TestReadStopsEarly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	if err := os.WriteFile(path, []byte("{\"Seq\":1}\n{\"Seq\":2}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := Read(path, func(e Event) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Read() = %v, want the callback's error", err)
+	}
+	if calls != 1 {
+		t.Errorf("callback ran %v times, want 1", calls)
+	}
+}
@@ -0,0 +1,169 @@
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// CombatResolver fights one battle between the units two players have in the
+// same location. rng is seeded from the war being fought, so every client
+// and server resolving the same war with the same resolver agree. Unit
+// power and terrain come from the scenario.
+type CombatResolver interface {
+	Fight(rng *rand.Rand, scenario *Scenario, loc Location, attacker, defender []Unit) BattleResult
+}
+
+// BattleResult is a battle's outcome, from the attacker's point of view, and
+// the units each side lost.
+type BattleResult struct {
+	Outcome        WarOutcome
+	AttackerPower  int
+	DefenderPower  int
+	AttackerLosses []Unit
+	DefenderLosses []Unit
+}
+
+// DeterministicResolver is the default combat model. The side with more
+// power wins. Each side then takes damage equal to the other side's power,
+// which kills its weakest units first; damage too small to kill the next
+// unit is wasted, so a much stronger army can win without losses.
+type DeterministicResolver struct{}
+
+func (DeterministicResolver) Fight(
+	_ *rand.Rand,
+	scenario *Scenario,
+	loc Location,
+	attacker, defender []Unit,
+) BattleResult {
+	return fight(attacker, defender, func(unit Unit) float64 {
+		return scenario.power(loc, unit)
+	})
+}
+
+// DiceResolver rolls a die for every unit, scaling its power by the roll
+// over the die's average, then fights like DeterministicResolver.
+type DiceResolver struct {
+	Sides int
+}
+
+func (r DiceResolver) Fight(
+	rng *rand.Rand,
+	scenario *Scenario,
+	loc Location,
+	attacker, defender []Unit,
+) BattleResult {
+	sides := max(r.Sides, 1)
+	average := float64(sides+1) / 2
+	return fight(attacker, defender, func(unit Unit) float64 {
+		roll := float64(rng.Intn(sides) + 1)
+		return scenario.power(loc, unit) * roll / average
+	})
+}
+
+type armyUnit struct {
+	unit  Unit
+	power float64
+}
+
+// fight scores both armies with power, which is called once per unit,
+// attackers first, in unit ID order.
+func fight(attacker, defender []Unit, power func(Unit) float64) BattleResult {
+	attackerArmy, attackerPower := score(attacker, power)
+	defenderArmy, defenderPower := score(defender, power)
+
+	result := BattleResult{
+		Outcome:        WarOutcomeDraw,
+		AttackerPower:  int(math.Round(attackerPower)),
+		DefenderPower:  int(math.Round(defenderPower)),
+		AttackerLosses: casualties(attackerArmy, defenderPower),
+		DefenderLosses: casualties(defenderArmy, attackerPower),
+	}
+	switch {
+	case result.AttackerPower > result.DefenderPower:
+		result.Outcome = WarOutcomeYouWon
+	case result.DefenderPower > result.AttackerPower:
+		result.Outcome = WarOutcomeOpponentWon
+	}
+	return result
+}
+
+func score(units []Unit, power func(Unit) float64) ([]armyUnit, float64) {
+	sorted := append([]Unit{}, units...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	army := []armyUnit{}
+	total := 0.0
+	for _, unit := range sorted {
+		p := power(unit)
+		army = append(army, armyUnit{unit: unit, power: p})
+		total += p
+	}
+	return army, total
+}
+
+// casualties kills units, weakest first, until damage can't kill the next
+// one.
+func casualties(army []armyUnit, damage float64) []Unit {
+	sort.SliceStable(army, func(i, j int) bool {
+		return army[i].power < army[j].power
+	})
+	killed := []Unit{}
+	for _, u := range army {
+		if damage < u.power {
+			break
+		}
+		damage -= u.power
+		killed = append(killed, u.unit)
+	}
+	return killed
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]CombatResolver{
+		"deterministic": DeterministicResolver{},
+		"dice":          DiceResolver{Sides: 6},
+	}
+)
+
+// DefaultCombatResolver is the name of the resolver used unless another is
+// chosen. Clients switch to the one the server names in its scenario.
+const DefaultCombatResolver = "deterministic"
+
+func defaultCombatResolver() CombatResolver {
+	r, _ := LookupCombatResolver(DefaultCombatResolver)
+	return r
+}
+
+func RegisterCombatResolver(name string, r CombatResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[name] = r
+}
+
+func LookupCombatResolver(name string) (CombatResolver, error) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	r, ok := resolvers[name]
+	if !ok {
+		return nil, fmt.Errorf("no combat resolver named %q", name)
+	}
+	return r, nil
+}
+
+// CombatResolvers lists the registered resolver names.
+func CombatResolvers() []string {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	names := []string{}
+	for name := range resolvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
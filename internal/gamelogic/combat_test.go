@@ -0,0 +1,161 @@
+package gamelogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeterministicResolverFight(t *testing.T) {
+	infantry := func(id int) Unit { return Unit{ID: id, Rank: RankInfantry} }
+	cavalry := func(id int) Unit { return Unit{ID: id, Rank: RankCavalry} }
+	artillery := func(id int) Unit { return Unit{ID: id, Rank: RankArtillery} }
+
+	tests := []struct {
+		name           string
+		loc            Location
+		attacker       []Unit
+		defender       []Unit
+		outcome        WarOutcome
+		attackerPower  int
+		defenderPower  int
+		attackerLosses []Unit
+		defenderLosses []Unit
+	}{
+		{
+			name:           "stronger attacker wins without losses",
+			loc:            "americas",
+			attacker:       []Unit{artillery(1)},
+			defender:       []Unit{infantry(1), infantry(2)},
+			outcome:        WarOutcomeYouWon,
+			attackerPower:  10,
+			defenderPower:  2,
+			attackerLosses: []Unit{},
+			defenderLosses: []Unit{infantry(1), infantry(2)},
+		},
+		{
+			name:           "stronger defender wins",
+			loc:            "americas",
+			attacker:       []Unit{infantry(1)},
+			defender:       []Unit{cavalry(1)},
+			outcome:        WarOutcomeOpponentWon,
+			attackerPower:  1,
+			defenderPower:  5,
+			attackerLosses: []Unit{infantry(1)},
+			defenderLosses: []Unit{},
+		},
+		{
+			name:           "equal armies draw and both die",
+			loc:            "americas",
+			attacker:       []Unit{cavalry(1)},
+			defender:       []Unit{cavalry(1)},
+			outcome:        WarOutcomeDraw,
+			attackerPower:  5,
+			defenderPower:  5,
+			attackerLosses: []Unit{cavalry(1)},
+			defenderLosses: []Unit{cavalry(1)},
+		},
+		{
+			name:           "weakest units die first",
+			loc:            "americas",
+			attacker:       []Unit{artillery(1), infantry(2)},
+			defender:       []Unit{cavalry(1), infantry(2)},
+			outcome:        WarOutcomeYouWon,
+			attackerPower:  11,
+			defenderPower:  6,
+			attackerLosses: []Unit{infantry(2)},
+			defenderLosses: []Unit{infantry(2), cavalry(1)},
+		},
+		{
+			name:           "terrain scales power",
+			loc:            "antarctica",
+			attacker:       []Unit{cavalry(1)},
+			defender:       []Unit{infantry(1), infantry(2), infantry(3)},
+			outcome:        WarOutcomeDraw,
+			attackerPower:  3,
+			defenderPower:  3,
+			attackerLosses: []Unit{cavalry(1)},
+			defenderLosses: []Unit{infantry(1), infantry(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DeterministicResolver{}.Fight(nil, DefaultScenario(), tt.loc, tt.attacker, tt.defender)
+			if result.Outcome != tt.outcome {
+				t.Errorf("outcome = %v, want %v", result.Outcome, tt.outcome)
+			}
+			if result.AttackerPower != tt.attackerPower || result.DefenderPower != tt.defenderPower {
+				t.Errorf(
+					"power = %v vs %v, want %v vs %v",
+					result.AttackerPower, result.DefenderPower, tt.attackerPower, tt.defenderPower,
+				)
+			}
+			if !reflect.DeepEqual(result.AttackerLosses, tt.attackerLosses) {
+				t.Errorf("attacker losses = %v, want %v", result.AttackerLosses, tt.attackerLosses)
+			}
+			if !reflect.DeepEqual(result.DefenderLosses, tt.defenderLosses) {
+				t.Errorf("defender losses = %v, want %v", result.DefenderLosses, tt.defenderLosses)
+			}
+		})
+	}
+}
+
+func TestCasualties(t *testing.T) {
+	army := func(powers ...float64) []armyUnit {
+		units := []armyUnit{}
+		for i, p := range powers {
+			units = append(units, armyUnit{unit: Unit{ID: i + 1}, power: p})
+		}
+		return units
+	}
+
+	tests := []struct {
+		name   string
+		army   []armyUnit
+		damage float64
+		want   []int
+	}{
+		{
+			name:   "no damage",
+			army:   army(1, 1),
+			damage: 0,
+			want:   []int{},
+		},
+		{
+			name:   "too little to kill anything",
+			army:   army(5),
+			damage: 4.9,
+			want:   []int{},
+		},
+		{
+			name:   "weakest first",
+			army:   army(10, 1, 5),
+			damage: 6,
+			want:   []int{2, 3},
+		},
+		{
+			name:   "leftover damage is wasted",
+			army:   army(1, 5, 1),
+			damage: 4,
+			want:   []int{1, 3},
+		},
+		{
+			name:   "everyone",
+			army:   army(1, 5, 10),
+			damage: 100,
+			want:   []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []int{}
+			for _, unit := range casualties(tt.army, tt.damage) {
+				ids = append(ids, unit.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("casualties = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
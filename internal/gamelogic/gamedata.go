@@ -1,5 +1,12 @@
 package gamelogic
 
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 type Player struct {
 	Username string
 	Units    map[int]Unit
@@ -13,40 +20,102 @@ const (
 	RankArtillery = "artillery"
 )
 
+// Unit IDs are only unique per player. A UnitKey, "username:id", names a
+// unit unambiguously across players. A moving unit's Path holds the
+// locations it has still to reach; its Location is the last one it reached,
+// or a border it is fighting on.
 type Unit struct {
 	ID       int
 	Rank     UnitRank
 	Location Location
+	Path     []Location
+}
+
+type UnitKey string
+
+func NewUnitKey(username string, id int) UnitKey {
+	return UnitKey(fmt.Sprintf("%s:%v", username, id))
 }
 
+// ParseUnitKey splits key into its username and unit ID.
+func ParseUnitKey(key UnitKey) (string, int, error) {
+	i := strings.LastIndex(string(key), ":")
+	if i < 1 {
+		return "", 0, fmt.Errorf("%s is not a unit key", key)
+	}
+	id, err := strconv.Atoi(string(key[i+1:]))
+	if err != nil || id < 1 {
+		return "", 0, fmt.Errorf("%s is not a unit key", key)
+	}
+	return string(key[:i]), id, nil
+}
+
+func unitKeys(username string, units []Unit) []UnitKey {
+	keys := []UnitKey{}
+	for _, unit := range units {
+		keys = append(keys, NewUnitKey(username, unit.ID))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// ArmyMoveSchemaVersion is bumped whenever the shape of ArmyMove changes in a
+// way older clients can't decode. Version 2 added Path.
+const ArmyMoveSchemaVersion = 2
+
+// ArmyMove sends units from the location they share along Path, one border
+// at a time, to ToLocation, the last location on the path.
 type ArmyMove struct {
 	Player     Player
 	Units      []Unit
 	ToLocation Location
+	Path       []Location
 }
 
-type RecognitionOfWar struct {
-	Attacker Player
-	Defender Player
+// UnitSpawned tells the server about a unit a player has spawned, so the
+// world it keeps knows the unit exists.
+type UnitSpawned struct {
+	Username string
+	Unit     Unit
 }
 
-type Location string
+// Tick is published by the server's game clock. Spawned holds the units
+// spawned on the tick, Moved every unit that moved, or stopped to fight,
+// during it, and Killed the units that died in its wars, where they died;
+// Balances is every player's resources once the tick's spawns are paid for
+// and its income earned. Together they are everything the tick changed.
+// Refused holds the spawns the server wouldn't deploy, and why.
+type Tick struct {
+	Number   int64
+	Spawned  []UnitPosition
+	Moved    []UnitPosition
+	Balances map[string]int
+	Refused  []SpawnRejection
+	Killed   []UnitPosition
+}
 
-func getAllRanks() map[UnitRank]struct{} {
-	return map[UnitRank]struct{}{
-		RankInfantry:  {},
-		RankCavalry:   {},
-		RankArtillery: {},
-	}
+// Roster is what the world says a player has. The server sends it to a
+// client when it joins, so the client plays with the units the world knows
+// about rather than the ones it saved.
+type Roster struct {
+	Username  string
+	Units     []Unit
+	Resources int
 }
 
-func getAllLocations() map[Location]struct{} {
-	return map[Location]struct{}{
-		"americas":   {},
-		"europe":     {},
-		"africa":     {},
-		"asia":       {},
-		"australia":  {},
-		"antarctica": {},
-	}
+type UnitPosition struct {
+	Username string
+	Unit     Unit
 }
+
+// RecognitionOfWar carries the seed every resolver of the war draws its
+// randomness from, so they all fight the same battles.
+type RecognitionOfWar struct {
+	Attacker Player
+	Defender Player
+	Seed     int64
+}
+
+type Location string
@@ -42,6 +42,14 @@ func PrintServerHelp() {
 	fmt.Println("Possible commands:")
 	fmt.Println("* pause")
 	fmt.Println("* resume")
+	fmt.Println("* status")
+	fmt.Println("* tick")
+	fmt.Println("* replay [time] [username]")
+	fmt.Println("* dlq list [n]")
+	fmt.Println("* dlq replay <position> <position>...")
+	fmt.Println("    example:")
+	fmt.Println("    dlq replay 1 3")
+	fmt.Println("* stats")
 	fmt.Println("* quit")
 	fmt.Println("* help")
 }
@@ -58,6 +66,23 @@ func GetInput() []string {
 	return strings.Fields(line)
 }
 
+// ReadInput calls GetInput in the background and sends every line of words
+// on the returned channel. The channel is closed once stdin is exhausted.
+func ReadInput() <-chan []string {
+	inputs := make(chan []string)
+	go func() {
+		defer close(inputs)
+		for {
+			words := GetInput()
+			if words == nil {
+				return
+			}
+			inputs <- words
+		}
+	}()
+	return inputs
+}
+
 func GetMaliciousLog() string {
 	possibleLogs := []string{
 		"Never interrupt your enemy when he is making a mistake.",
@@ -86,7 +111,15 @@ func (gs *GameState) CommandStatus() {
 
 	p := gs.GetPlayerSnap()
 	fmt.Printf("You are %s, and you have %d units.\n", p.Username, len(p.Units))
+	fmt.Printf("You have %v resources, %v of them not yet spent.\n", gs.getResources(), gs.availableResources())
 	for _, unit := range p.Units {
-		fmt.Printf("* %v: %v, %v\n", unit.ID, unit.Location, unit.Rank)
+		fmt.Printf("* %v (%s): %v, %v", unit.ID, NewUnitKey(p.Username, unit.ID), unit.Location, unit.Rank)
+		if len(unit.Path) > 0 {
+			fmt.Printf(", heading for %v", unit.Path)
+		}
+		fmt.Println()
+	}
+	for _, unit := range gs.getPendingSpawns() {
+		fmt.Printf("* %v (%s): %v, %v, waiting for the next tick\n", unit.ID, NewUnitKey(p.Username, unit.ID), unit.Location, unit.Rank)
 	}
 }
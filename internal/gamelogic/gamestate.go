@@ -5,9 +5,21 @@ import (
 )
 
 type GameState struct {
-	Player Player
-	Paused bool
-	mu     *sync.RWMutex
+	Player     Player
+	Paused     bool
+	NextUnitID int
+	Resources  int
+	mu         *sync.RWMutex
+
+	savePath string
+	// saveMu keeps saves from overlapping, so an older snapshot is never
+	// renamed over a newer one.
+	saveMu   *sync.Mutex
+	combat   CombatResolver
+	scenario *Scenario
+
+	// Spawned units wait here until the tick that deploys them.
+	pendingSpawns map[int]Unit
 }
 
 func NewGameState(username string) *GameState {
@@ -16,21 +28,29 @@ func NewGameState(username string) *GameState {
 			Username: username,
 			Units:    map[int]Unit{},
 		},
-		Paused: false,
-		mu:     &sync.RWMutex{},
+		Paused:     false,
+		NextUnitID: 1,
+		Resources:  DefaultScenario().StartingResources,
+		mu:         &sync.RWMutex{},
+		saveMu:     &sync.Mutex{},
+		scenario:   DefaultScenario(),
+
+		pendingSpawns: map[int]Unit{},
 	}
 }
 
 func (gs *GameState) resumeGame() {
 	gs.mu.Lock()
-	defer gs.mu.Unlock()
 	gs.Paused = false
+	gs.mu.Unlock()
+	gs.changed()
 }
 
 func (gs *GameState) pauseGame() {
 	gs.mu.Lock()
-	defer gs.mu.Unlock()
 	gs.Paused = true
+	gs.mu.Unlock()
+	gs.changed()
 }
 
 func (gs *GameState) isPaused() bool {
@@ -41,24 +61,141 @@ func (gs *GameState) isPaused() bool {
 
 func (gs *GameState) addUnit(u Unit) {
 	gs.mu.Lock()
-	defer gs.mu.Unlock()
 	gs.Player.Units[u.ID] = u
+	gs.NextUnitID = max(gs.NextUnitID, u.ID+1)
+	gs.mu.Unlock()
+	gs.changed()
+}
+
+// queueSpawn gives a new unit the next unused ID and holds it until the
+// server deploys it on the next tick. IDs are never reused, even after the
+// units holding them are killed.
+func (gs *GameState) queueSpawn(rank UnitRank, loc Location) Unit {
+	gs.mu.Lock()
+	unit := Unit{
+		ID:       gs.NextUnitID,
+		Rank:     rank,
+		Location: loc,
+	}
+	gs.pendingSpawns[unit.ID] = unit
+	gs.NextUnitID++
+	gs.mu.Unlock()
+	gs.changed()
+	return unit
+}
+
+// deploy adds a unit the server spawned, if it is one we queued.
+func (gs *GameState) deploy(u Unit) bool {
+	gs.mu.Lock()
+	_, pending := gs.pendingSpawns[u.ID]
+	_, exists := gs.Player.Units[u.ID]
+	delete(gs.pendingSpawns, u.ID)
+	gs.mu.Unlock()
+	if !pending && !exists {
+		return false
+	}
+	gs.addUnit(u)
+	return pending
+}
+
+// refuse forgets a unit we queued that the server wouldn't deploy.
+func (gs *GameState) refuse(u Unit) bool {
+	gs.mu.Lock()
+	_, pending := gs.pendingSpawns[u.ID]
+	delete(gs.pendingSpawns, u.ID)
+	gs.mu.Unlock()
+	return pending
+}
+
+func (gs *GameState) getPendingSpawns() []Unit {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	units := []Unit{}
+	for _, id := range sortedUnitIDs(gs.pendingSpawns) {
+		units = append(units, gs.pendingSpawns[id])
+	}
+	return units
+}
+
+// availableResources is what the player can still spend: their balance less
+// the cost of the units waiting to be deployed, which the server takes on
+// the next tick.
+func (gs *GameState) availableResources() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	available := gs.Resources
+	for _, unit := range gs.pendingSpawns {
+		spec, _ := gs.scenario.Unit(unit.Rank)
+		available -= spec.Cost
+	}
+	return available
 }
 
-func (gs *GameState) removeUnitsInLocation(loc Location) {
+func (gs *GameState) getResources() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.Resources
+}
+
+func (gs *GameState) setResources(resources int) {
+	gs.mu.Lock()
+	changed := gs.Resources != resources
+	gs.Resources = resources
+	gs.mu.Unlock()
+	if changed {
+		gs.changed()
+	}
+}
+
+func (gs *GameState) removeUnits(units []Unit) {
+	gs.mu.Lock()
+	for _, unit := range units {
+		delete(gs.Player.Units, unit.ID)
+	}
+	gs.mu.Unlock()
+	gs.changed()
+}
+
+// SetCombatResolver changes how the player's wars are fought. It must match
+// the resolver the server uses.
+func (gs *GameState) SetCombatResolver(r CombatResolver) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	for k, v := range gs.Player.Units {
-		if v.Location == loc {
-			delete(gs.Player.Units, k)
-		}
+	gs.combat = r
+}
+
+// SetScenario changes the map and units the player's commands are checked
+// against. Clients use the scenario the server broadcasts. A player who has
+// never spawned a unit starts with the scenario's resources.
+func (gs *GameState) SetScenario(s *Scenario) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.scenario = s
+	if gs.NextUnitID == 1 {
+		gs.Resources = s.StartingResources
 	}
 }
 
+func (gs *GameState) Scenario() *Scenario {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.scenario
+}
+
+func (gs *GameState) combatResolver() CombatResolver {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	if gs.combat == nil {
+		return defaultCombatResolver()
+	}
+	return gs.combat
+}
+
 func (gs *GameState) UpdateUnit(u Unit) {
 	gs.mu.Lock()
-	defer gs.mu.Unlock()
 	gs.Player.Units[u.ID] = u
+	gs.mu.Unlock()
+	gs.changed()
 }
 
 func (gs *GameState) GetUsername() string {
@@ -24,6 +24,16 @@ func WriteLog(gamelog routing.GameLog) error {
 	defer f.Close()
 
 	str := fmt.Sprintf("%v %v: %v\n", gamelog.CurrentTime.Format(time.RFC3339), gamelog.Username, gamelog.Message)
+	for _, battle := range gamelog.Battles {
+		winner := battle.Winner
+		if winner == "" {
+			winner = "draw"
+		}
+		str += fmt.Sprintf(
+			"    %v: %v (%v vs %v), killed %v\n",
+			battle.Location, winner, battle.AttackerPower, battle.DefenderPower, battle.Casualties,
+		)
+	}
 	_, err = f.WriteString(str)
 	if err != nil {
 		return fmt.Errorf("could not write to logs file: %v", err)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 type MoveOutcome int
@@ -11,16 +12,17 @@ type MoveOutcome int
 const (
 	MoveOutcomeSamePlayer MoveOutcome = iota
 	MoveOutComeSafe
-	MoveOutcomeMakeWar
 )
 
+// HandleMove reports another player's army setting off. Armies only fight
+// once they meet, which the server works out as the clock ticks.
 func (gs *GameState) HandleMove(move ArmyMove) MoveOutcome {
 	defer fmt.Println("------------------------")
 	player := gs.GetPlayerSnap()
 
 	fmt.Println()
 	fmt.Println("==== Move Detected ====")
-	fmt.Printf("%s is moving %v unit(s) to %s\n", move.Player.Username, len(move.Units), move.ToLocation)
+	fmt.Printf("%s is moving %v unit(s) to %s via %v\n", move.Player.Username, len(move.Units), move.ToLocation, move.Path)
 	for _, unit := range move.Units {
 		fmt.Printf("* %v\n", unit.Rank)
 	}
@@ -29,24 +31,16 @@ func (gs *GameState) HandleMove(move ArmyMove) MoveOutcome {
 		return MoveOutcomeSamePlayer
 	}
 
-	overlappingLocation := getOverlappingLocation(player, move.Player)
-	if overlappingLocation != "" {
-		fmt.Printf("You have units in %s! You are at war with %s!\n", overlappingLocation, move.Player.Username)
-		return MoveOutcomeMakeWar
-	}
-	fmt.Printf("You are safe from %s's units.\n", move.Player.Username)
-	return MoveOutComeSafe
-}
-
-func getOverlappingLocation(p1 Player, p2 Player) Location {
-	for _, u1 := range p1.Units {
-		for _, u2 := range p2.Units {
-			if u1.Location == u2.Location {
-				return u1.Location
+	for _, unit := range player.Units {
+		for _, loc := range move.Path {
+			if unit.Location == loc {
+				fmt.Printf("You have units in %s, in the path of %s's army!\n", loc, move.Player.Username)
+				return MoveOutComeSafe
 			}
 		}
 	}
-	return ""
+	fmt.Printf("You are safe from %s's units.\n", move.Player.Username)
+	return MoveOutComeSafe
 }
 
 func (gs *GameState) CommandMove(words []string) (ArmyMove, error) {
@@ -57,36 +51,86 @@ func (gs *GameState) CommandMove(words []string) (ArmyMove, error) {
 		return ArmyMove{}, errors.New("usage: move <location> <unitID> <unitID> <unitID> etc")
 	}
 	newLocation := Location(words[1])
-	locations := getAllLocations()
-	if _, ok := locations[newLocation]; !ok {
+	if !gs.Scenario().HasLocation(newLocation) {
 		return ArmyMove{}, fmt.Errorf("error: %s is not a valid location", newLocation)
 	}
 	unitIDs := []int{}
 	for _, word := range words[2:] {
-		id := word
-		unitID, err := strconv.Atoi(id)
+		unitID, err := parseUnitID(gs.GetUsername(), word)
 		if err != nil {
-			return ArmyMove{}, fmt.Errorf("error: %s is not a valid unit ID", id)
+			return ArmyMove{}, err
 		}
 		unitIDs = append(unitIDs, unitID)
 	}
 
-	newUnits := []Unit{}
+	units := []Unit{}
 	for _, unitID := range unitIDs {
 		unit, ok := gs.GetUnit(unitID)
 		if !ok {
 			return ArmyMove{}, fmt.Errorf("error: unit with ID %v not found", unitID)
 		}
-		unit.Location = newLocation
-		gs.UpdateUnit(unit)
-		newUnits = append(newUnits, unit)
+		if IsBorder(unit.Location) {
+			return ArmyMove{}, fmt.Errorf("error: unit %v is fighting at %s", unitID, unit.Location)
+		}
+		if len(units) > 0 && unit.Location != units[0].Location {
+			return ArmyMove{}, errors.New("error: units can only move together from the same location")
+		}
+		units = append(units, unit)
+	}
+
+	from := units[0].Location
+	if from == newLocation {
+		return ArmyMove{}, fmt.Errorf("error: the units are already in %s", newLocation)
+	}
+	path, ok := gs.Scenario().ShortestPath(from, newLocation)
+	if !ok {
+		return ArmyMove{}, fmt.Errorf("error: there is no way from %s to %s", from, newLocation)
+	}
+
+	// The units set off on the next tick, when the server resolves every
+	// move made since the last one together.
+	ticks := 0
+	for _, unit := range units {
+		ticks = max(ticks, gs.Scenario().TravelTicks(unit.Rank, path))
 	}
 
 	mv := ArmyMove{
 		ToLocation: newLocation,
-		Units:      newUnits,
+		Units:      units,
 		Player:     gs.GetPlayerSnap(),
+		Path:       path,
 	}
-	fmt.Printf("Moved %v units to %s\n", len(mv.Units), mv.ToLocation)
+	fmt.Printf("Moving %v units to %s via %v on the next tick, arriving in %v tick(s)\n", len(mv.Units), mv.ToLocation, path, ticks)
 	return mv, nil
 }
+
+// parseUnitID accepts either a bare unit ID or one of username's unit keys.
+func parseUnitID(username, word string) (int, error) {
+	if !strings.Contains(word, ":") {
+		unitID, err := strconv.Atoi(word)
+		if err != nil {
+			return 0, fmt.Errorf("error: %s is not a valid unit ID", word)
+		}
+		return unitID, nil
+	}
+	owner, unitID, err := ParseUnitKey(UnitKey(word))
+	if err != nil {
+		return 0, fmt.Errorf("error: %s is not a valid unit ID", word)
+	}
+	if owner != username {
+		return 0, fmt.Errorf("error: unit %s belongs to %s", word, owner)
+	}
+	return unitID, nil
+}
+
+func (gs *GameState) HandleMoveRejection(mr MoveRejection) {
+	defer fmt.Println("------------------------")
+	fmt.Println()
+	fmt.Println("==== Move Rejected ====")
+	keys := []UnitKey{}
+	for _, id := range mr.UnitIDs {
+		keys = append(keys, NewUnitKey(mr.Username, id))
+	}
+	fmt.Printf("The server rejected your move of unit(s) %v to %s.\n", keys, mr.ToLocation)
+	fmt.Printf("Reason: %s (%s)\n", mr.Reason, mr.Detail)
+}
@@ -0,0 +1,412 @@
+package gamelogic
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+)
+
+// crossing is a unit stepping from one location to the next on its path.
+type crossing struct {
+	username string
+	id       int
+	from     Location
+	to       Location
+}
+
+// TickResult is everything a tick did: what to tell clients, what became of
+// the moves queued since the last tick, and the wars fought. Wars holds each
+// war as declared, with the armies and seed it was fought with, and Results
+// how each one went.
+type TickResult struct {
+	Tick    Tick
+	Moves   []MoveResult
+	Wars    []RecognitionOfWar
+	Results []War
+}
+
+// Tick resolves the spawns and moves queued since the last tick all at once,
+// in the order they arrived, then moves every unit with a path as far as its
+// speed allows. Fast units take their extra steps after slow ones have taken
+// their first, so armies meet at the same step whatever their speeds. Armies
+// crossing the same border in opposite directions stop on the border to
+// fight, as does an army crossing a border an enemy is already fighting on.
+// Units entering a location an enemy holds stop there for the tick.
+//
+// Spawns are paid for as they are resolved. One the player can't afford, or
+// that is otherwise invalid, is refused and the tick says why. Once
+// everything has moved, each player earns the income of every location they
+// hold alone.
+//
+// A player whose units reached a location, or border, another player holds
+// attacks that player, and the war is fought there and then, before anyone
+// earns their income. While the game is paused nothing moves and queued
+// orders wait.
+func (w *World) Tick(number int64) TickResult {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := TickResult{
+		Tick: Tick{
+			Number:   number,
+			Spawned:  []UnitPosition{},
+			Moved:    []UnitPosition{},
+			Balances: map[string]int{},
+			Refused:  []SpawnRejection{},
+			Killed:   []UnitPosition{},
+		},
+		Moves:   []MoveResult{},
+		Wars:    []RecognitionOfWar{},
+		Results: []War{},
+	}
+	if w.paused {
+		return result
+	}
+	tick := &result.Tick
+
+	for _, qs := range w.queuedSpawns {
+		us := qs.spawn
+		err := w.spawnLocked(qs.sender, us)
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			log.Printf("rejected spawn from %s: %v", qs.sender, err)
+			tick.Refused = append(tick.Refused, SpawnRejection{
+				Username: qs.sender,
+				Unit:     us.Unit,
+				Reason:   invalid.Reason,
+				Detail:   invalid.Detail,
+			})
+			continue
+		}
+		tick.Spawned = append(tick.Spawned, UnitPosition{
+			Username: us.Username,
+			Unit:     w.players[us.Username][us.Unit.ID],
+		})
+	}
+	w.queuedSpawns = nil
+
+	for _, qm := range w.queuedMoves {
+		accepted, err := w.moveLocked(qm.sender, qm.move)
+		if err != nil {
+			accepted = qm.move
+		}
+		result.Moves = append(result.Moves, MoveResult{
+			Sender:        qm.sender,
+			Move:          accepted,
+			CorrelationID: qm.correlationID,
+			Err:           err,
+		})
+	}
+	w.queuedMoves = nil
+
+	maxSpeed := 1
+	for _, unit := range w.scenario.Units {
+		maxSpeed = max(maxSpeed, unit.Speed)
+	}
+
+	usernames := w.usernamesLocked()
+	moved := map[UnitKey]struct{}{}
+	stopped := map[UnitKey]struct{}{}
+	arrived := map[Location]map[string]struct{}{}
+
+	for step := 1; step <= maxSpeed; step++ {
+		crossings := []crossing{}
+		for _, username := range usernames {
+			for _, id := range sortedUnitIDs(w.players[username]) {
+				unit := w.players[username][id]
+				key := NewUnitKey(username, id)
+				if _, ok := stopped[key]; ok || len(unit.Path) == 0 {
+					continue
+				}
+				spec, _ := w.scenario.Unit(unit.Rank)
+				if max(spec.Speed, 1) < step {
+					continue
+				}
+				crossings = append(crossings, crossing{
+					username: username,
+					id:       id,
+					from:     unit.Location,
+					to:       unit.Path[0],
+				})
+			}
+		}
+
+		for _, c := range crossings {
+			unit := w.players[c.username][c.id]
+			key := NewUnitKey(c.username, c.id)
+			moved[key] = struct{}{}
+
+			// A unit already on a border finishes crossing it.
+			if !IsBorder(c.from) && w.contestedLocked(c, crossings) {
+				border := BorderLocation(c.from, c.to)
+				unit.Location = border
+				stopped[key] = struct{}{}
+				markArrived(arrived, border, c.username)
+			} else {
+				unit.Location = c.to
+				unit.Path = unit.Path[1:]
+				if len(unit.Path) == 0 {
+					unit.Path = nil
+				}
+				markArrived(arrived, c.to, c.username)
+				if w.enemyInLocked(c.username, c.to) {
+					stopped[key] = struct{}{}
+				}
+			}
+			w.players[c.username][c.id] = unit
+		}
+	}
+
+	for _, username := range usernames {
+		for _, id := range sortedUnitIDs(w.players[username]) {
+			if _, ok := moved[NewUnitKey(username, id)]; ok {
+				tick.Moved = append(tick.Moved, UnitPosition{
+					Username: username,
+					Unit:     w.players[username][id],
+				})
+			}
+		}
+	}
+
+	for _, contact := range w.contactsLocked(usernames, arrived) {
+		// Each war is fought with the armies as the wars before it left them.
+		rw := RecognitionOfWar{
+			Attacker: w.playerLocked(contact[0]),
+			Defender: w.playerLocked(contact[1]),
+			Seed:     rand.Int63(),
+		}
+		war := resolveWar(w.combat, w.scenario, rw.Seed, rw.Attacker, rw.Defender)
+		for _, battle := range war.Battles {
+			tick.Killed = append(tick.Killed, w.killLocked(war.Attacker, battle.AttackerLosses)...)
+			tick.Killed = append(tick.Killed, w.killLocked(war.Defender, battle.DefenderLosses)...)
+		}
+		result.Wars = append(result.Wars, rw)
+		result.Results = append(result.Results, war)
+	}
+
+	w.earnLocked()
+	for username, balance := range w.resources {
+		tick.Balances[username] = balance
+	}
+	return result
+}
+
+// killLocked removes username's units and returns where they died.
+func (w *World) killLocked(username string, units []Unit) []UnitPosition {
+	killed := []UnitPosition{}
+	for _, unit := range units {
+		killed = append(killed, UnitPosition{
+			Username: username,
+			Unit:     unit,
+		})
+	}
+	w.removeUnitsLocked(username, units)
+	return killed
+}
+
+// earnLocked pays every player the income of the locations only they have
+// units in. Contested locations and borders earn nobody anything.
+func (w *World) earnLocked() {
+	holders := map[Location]map[string]struct{}{}
+	for username, units := range w.players {
+		for _, unit := range units {
+			markArrived(holders, unit.Location, username)
+		}
+	}
+	for loc, usernames := range holders {
+		if len(usernames) != 1 || IsBorder(loc) {
+			continue
+		}
+		for username := range usernames {
+			w.resources[username] += w.scenario.Income(loc)
+		}
+	}
+}
+
+// contestedLocked reports whether c runs into an enemy on the border it is
+// crossing: one crossing the other way at the same step, or one already
+// stopped there.
+func (w *World) contestedLocked(c crossing, crossings []crossing) bool {
+	for _, other := range crossings {
+		if other.username != c.username && other.from == c.to && other.to == c.from {
+			return true
+		}
+	}
+	return w.enemyInLocked(c.username, BorderLocation(c.from, c.to))
+}
+
+func (w *World) enemyInLocked(username string, loc Location) bool {
+	for other, units := range w.players {
+		if other == username {
+			continue
+		}
+		for _, unit := range units {
+			if unit.Location == loc {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contactsLocked returns an attacker and defender for every pair of players
+// where one arrived where the other has units. Each pair fights at most one
+// war a tick, which covers every location they share.
+func (w *World) contactsLocked(usernames []string, arrived map[Location]map[string]struct{}) [][2]string {
+	occupied := map[Location]map[string]struct{}{}
+	for username, units := range w.players {
+		for _, unit := range units {
+			markArrived(occupied, unit.Location, username)
+		}
+	}
+
+	locations := []Location{}
+	for loc := range arrived {
+		locations = append(locations, loc)
+	}
+	sortLocations(locations)
+
+	contacts := [][2]string{}
+	declared := map[[2]string]struct{}{}
+	for _, loc := range locations {
+		for _, attacker := range usernames {
+			if _, ok := arrived[loc][attacker]; !ok {
+				continue
+			}
+			for _, defender := range usernames {
+				if _, ok := occupied[loc][defender]; !ok || defender == attacker {
+					continue
+				}
+				pair := [2]string{min(attacker, defender), max(attacker, defender)}
+				if _, ok := declared[pair]; ok {
+					continue
+				}
+				declared[pair] = struct{}{}
+				contacts = append(contacts, [2]string{attacker, defender})
+			}
+		}
+	}
+	return contacts
+}
+
+// ApplyTick spawns and moves units to where a tick says they are, removes
+// the units its wars killed, and sets each player's resources to the tick's
+// balance, without resolving anything itself, so replaying the ticks a
+// server published rebuilds the world it had.
+func (w *World) ApplyTick(t Tick) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, pos := range t.Spawned {
+		if w.players[pos.Username] == nil {
+			w.players[pos.Username] = map[int]Unit{}
+		}
+		w.players[pos.Username][pos.Unit.ID] = pos.Unit
+	}
+	for _, pos := range t.Moved {
+		units, ok := w.players[pos.Username]
+		if !ok {
+			continue
+		}
+		if _, ok := units[pos.Unit.ID]; ok {
+			units[pos.Unit.ID] = pos.Unit
+		}
+	}
+	for _, pos := range t.Killed {
+		delete(w.players[pos.Username], pos.Unit.ID)
+	}
+	for username, balance := range t.Balances {
+		w.resources[username] = balance
+	}
+}
+
+func (w *World) usernamesLocked() []string {
+	usernames := []string{}
+	for username := range w.players {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+	return usernames
+}
+
+func sortedUnitIDs(units map[int]Unit) []int {
+	ids := []int{}
+	for id := range units {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func markArrived(arrived map[Location]map[string]struct{}, loc Location, username string) {
+	if arrived[loc] == nil {
+		arrived[loc] = map[string]struct{}{}
+	}
+	arrived[loc][username] = struct{}{}
+}
+
+// HandleTick deploys the player's spawned units, moves the player's units to
+// where the server says they are, removes those killed in its wars and
+// updates the player's resources. It reports whether it told the player
+// about anything.
+func (gs *GameState) HandleTick(t Tick) bool {
+	username := gs.GetUsername()
+	reported := false
+	for _, sr := range t.Refused {
+		if sr.Username != username {
+			continue
+		}
+		if gs.refuse(sr.Unit) {
+			fmt.Printf("Unit %s was not deployed: %s (%s).\n", NewUnitKey(username, sr.Unit.ID), sr.Reason, sr.Detail)
+			reported = true
+		}
+	}
+	for _, pos := range t.Spawned {
+		if pos.Username != username {
+			continue
+		}
+		if gs.deploy(pos.Unit) {
+			fmt.Printf("Unit %s has been deployed in %s.\n", NewUnitKey(username, pos.Unit.ID), pos.Unit.Location)
+			reported = true
+		}
+	}
+	for _, pos := range t.Moved {
+		if pos.Username != username {
+			continue
+		}
+		if _, ok := gs.GetUnit(pos.Unit.ID); !ok {
+			continue
+		}
+		gs.UpdateUnit(pos.Unit)
+		reported = true
+
+		key := NewUnitKey(username, pos.Unit.ID)
+		switch {
+		case IsBorder(pos.Unit.Location):
+			fmt.Printf("Unit %s has met the enemy at %s.\n", key, pos.Unit.Location)
+		case len(pos.Unit.Path) == 0:
+			fmt.Printf("Unit %s has arrived in %s.\n", key, pos.Unit.Location)
+		default:
+			fmt.Printf("Unit %s is passing through %s.\n", key, pos.Unit.Location)
+		}
+	}
+	killed := []Unit{}
+	for _, pos := range t.Killed {
+		if pos.Username != username {
+			continue
+		}
+		if _, ok := gs.GetUnit(pos.Unit.ID); ok {
+			killed = append(killed, pos.Unit)
+		}
+	}
+	if len(killed) > 0 {
+		gs.removeUnits(killed)
+		fmt.Printf("Units killed in battle: %v\n", unitKeys(username, killed))
+		reported = true
+	}
+	if balance, ok := t.Balances[username]; ok {
+		gs.setResources(balance)
+	}
+	return reported
+}
@@ -0,0 +1,409 @@
+package gamelogic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func spawnOrder(username string, id int, rank UnitRank, loc Location) UnitSpawned {
+	return UnitSpawned{
+		Username: username,
+		Unit:     Unit{ID: id, Rank: rank, Location: loc},
+	}
+}
+
+func moveOrder(username string, to Location, ids ...int) ArmyMove {
+	mv := ArmyMove{
+		Player:     Player{Username: username},
+		ToLocation: to,
+	}
+	for _, id := range ids {
+		mv.Units = append(mv.Units, Unit{ID: id})
+	}
+	return mv
+}
+
+func position(username string, id int, rank UnitRank, loc Location, path ...Location) UnitPosition {
+	if len(path) == 0 {
+		path = nil
+	}
+	return UnitPosition{
+		Username: username,
+		Unit:     Unit{ID: id, Rank: rank, Location: loc, Path: path},
+	}
+}
+
+// tickAfter spawns before on a first tick, then queues spawns and moves and
+// returns what the second tick did.
+func tickAfter(before, spawns []UnitSpawned, moves []ArmyMove) TickResult {
+	world := NewWorld()
+	world.SetCombatResolver(DeterministicResolver{})
+	for _, us := range before {
+		world.QueueSpawn(us.Username, us)
+	}
+	world.Tick(1)
+
+	for _, us := range spawns {
+		world.QueueSpawn(us.Username, us)
+	}
+	for _, mv := range moves {
+		// Fill in the ranks, as the player's client would.
+		player, _ := world.Player(mv.Player.Username)
+		for i, unit := range mv.Units {
+			if known, ok := player.Units[unit.ID]; ok && unit.Rank == "" {
+				mv.Units[i].Rank = known.Rank
+			}
+		}
+		world.QueueMove(mv.Player.Username, mv, "")
+	}
+	return world.Tick(2)
+}
+
+func checkPositions(t *testing.T, field string, got, want []UnitPosition) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func checkRefused(t *testing.T, got []SpawnRejection, want []RejectionReason) {
+	t.Helper()
+	reasons := []RejectionReason{}
+	for _, sr := range got {
+		reasons = append(reasons, sr.Reason)
+	}
+	if len(reasons) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("refused for %v, want %v", reasons, want)
+	}
+}
+
+func TestWorldTick(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []UnitSpawned
+		moves    []ArmyMove
+		moved    []UnitPosition
+		killed   []UnitPosition
+		rejected RejectionReason
+	}{
+		{
+			name:   "infantry crosses one border a tick",
+			before: []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "americas")},
+			moves:  []ArmyMove{moveOrder("alice", "australia", 1)},
+			moved:  []UnitPosition{position("alice", 1, RankInfantry, "antarctica", "australia")},
+		},
+		{
+			name:   "cavalry crosses two borders a tick",
+			before: []UnitSpawned{spawnOrder("alice", 1, RankCavalry, "americas")},
+			moves:  []ArmyMove{moveOrder("alice", "australia", 1)},
+			moved:  []UnitPosition{position("alice", 1, RankCavalry, "australia")},
+		},
+		{
+			name: "units entering an enemy's location stop there",
+			before: []UnitSpawned{
+				spawnOrder("alice", 1, RankCavalry, "americas"),
+				spawnOrder("bob", 1, RankCavalry, "antarctica"),
+			},
+			moves: []ArmyMove{moveOrder("alice", "australia", 1)},
+			moved: []UnitPosition{position("alice", 1, RankCavalry, "antarctica", "australia")},
+			killed: []UnitPosition{
+				position("alice", 1, RankCavalry, "antarctica", "australia"),
+				position("bob", 1, RankCavalry, "antarctica"),
+			},
+		},
+		{
+			name: "armies crossing a border fight on it",
+			before: []UnitSpawned{
+				spawnOrder("alice", 1, RankInfantry, "americas"),
+				spawnOrder("bob", 1, RankInfantry, "europe"),
+			},
+			moves: []ArmyMove{
+				moveOrder("alice", "europe", 1),
+				moveOrder("bob", "americas", 1),
+			},
+			moved: []UnitPosition{
+				position("alice", 1, RankInfantry, "americas|europe", "europe"),
+				position("bob", 1, RankInfantry, "americas|europe", "americas"),
+			},
+			killed: []UnitPosition{
+				position("alice", 1, RankInfantry, "americas|europe", "europe"),
+				position("bob", 1, RankInfantry, "americas|europe", "americas"),
+			},
+		},
+		{
+			name: "the attacker takes the location",
+			before: []UnitSpawned{
+				spawnOrder("alice", 1, RankCavalry, "americas"),
+				spawnOrder("bob", 1, RankInfantry, "europe"),
+			},
+			moves:  []ArmyMove{moveOrder("alice", "europe", 1)},
+			moved:  []UnitPosition{position("alice", 1, RankCavalry, "europe")},
+			killed: []UnitPosition{position("bob", 1, RankInfantry, "europe")},
+		},
+		{
+			name:     "moves of units the player doesn't have are rejected",
+			before:   []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "americas")},
+			moves:    []ArmyMove{moveOrder("alice", "europe", 2)},
+			rejected: RejectUnknownUnit,
+		},
+		{
+			name:   "moves of units with the wrong rank are rejected",
+			before: []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "americas")},
+			moves: []ArmyMove{{
+				Player:     Player{Username: "alice"},
+				Units:      []Unit{{ID: 1, Rank: RankArtillery}},
+				ToLocation: "europe",
+			}},
+			rejected: RejectForgedUnit,
+		},
+		{
+			name:     "moves off the map are rejected",
+			before:   []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "americas")},
+			moves:    []ArmyMove{moveOrder("alice", "atlantis", 1)},
+			rejected: RejectInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tickAfter(tt.before, nil, tt.moves)
+			checkPositions(t, "moved", result.Tick.Moved, tt.moved)
+			checkPositions(t, "killed", result.Tick.Killed, tt.killed)
+			if len(result.Wars) != len(result.Results) {
+				t.Errorf("%v wars declared but %v fought", len(result.Wars), len(result.Results))
+			}
+			for _, mr := range result.Moves {
+				var invalid *ValidationError
+				if !errors.As(mr.Err, &invalid) {
+					if tt.rejected != "" {
+						t.Errorf("move error = %v, want a %s rejection", mr.Err, tt.rejected)
+					}
+					continue
+				}
+				if invalid.Reason != tt.rejected {
+					t.Errorf("move rejected for %s, want %q", invalid.Reason, tt.rejected)
+				}
+			}
+		})
+	}
+}
+
+func TestWorldTickSpawns(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  []UnitSpawned
+		spawns  []UnitSpawned
+		spawned []UnitPosition
+		refused []RejectionReason
+	}{
+		{
+			name: "spawns only",
+			spawns: []UnitSpawned{
+				spawnOrder("alice", 1, RankInfantry, "americas"),
+				spawnOrder("bob", 1, RankCavalry, "europe"),
+			},
+			spawned: []UnitPosition{
+				position("alice", 1, RankInfantry, "americas"),
+				position("bob", 1, RankCavalry, "europe"),
+			},
+		},
+		{
+			name:    "spawns of units the world already has are refused",
+			before:  []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "americas")},
+			spawns:  []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "europe")},
+			refused: []RejectionReason{RejectUnitExists},
+		},
+		{
+			name:    "spawns off the map are refused",
+			spawns:  []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "atlantis")},
+			refused: []RejectionReason{RejectInvalidLocation},
+		},
+		{
+			name:    "spawns of unknown ranks are refused",
+			spawns:  []UnitSpawned{spawnOrder("alice", 1, "dragon", "americas")},
+			refused: []RejectionReason{RejectInvalidUnit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tick := tickAfter(tt.before, tt.spawns, nil).Tick
+			checkPositions(t, "spawned", tick.Spawned, tt.spawned)
+			checkRefused(t, tick.Refused, tt.refused)
+		})
+	}
+}
+
+func TestWorldTickPaused(t *testing.T) {
+	world := NewWorld()
+	world.QueueSpawn("alice", spawnOrder("alice", 1, RankInfantry, "americas"))
+
+	world.SetPaused(true)
+	if tick := world.Tick(1).Tick; len(tick.Spawned) != 0 {
+		t.Errorf("spawned %v while paused", tick.Spawned)
+	}
+	world.SetPaused(false)
+	if tick := world.Tick(2).Tick; len(tick.Spawned) != 1 {
+		t.Errorf("spawned %v after resuming, want the queued unit", tick.Spawned)
+	}
+}
+
+func TestGameStateHandleTick(t *testing.T) {
+	infantry := Unit{ID: 1, Rank: RankInfantry, Location: "americas"}
+	moved := Unit{ID: 1, Rank: RankInfantry, Location: "antarctica", Path: []Location{"australia"}}
+
+	tests := []struct {
+		name string
+		// pending is queued to spawn, units already deployed.
+		pending  []Unit
+		units    []Unit
+		tick     Tick
+		reported bool
+		want     map[int]Unit
+	}{
+		{
+			name:     "spawns only",
+			pending:  []Unit{infantry},
+			tick:     Tick{Spawned: []UnitPosition{{Username: "alice", Unit: infantry}}},
+			reported: true,
+			want:     map[int]Unit{1: infantry},
+		},
+		{
+			name: "other players' spawns",
+			tick: Tick{Spawned: []UnitPosition{{Username: "bob", Unit: infantry}}},
+			want: map[int]Unit{},
+		},
+		{
+			name:    "refused spawns",
+			pending: []Unit{infantry},
+			tick: Tick{Refused: []SpawnRejection{{
+				Username: "alice",
+				Unit:     infantry,
+				Reason:   RejectUnitExists,
+				Detail:   "unit alice_1 already exists as infantry",
+			}}},
+			reported: true,
+			want:     map[int]Unit{},
+		},
+		{
+			name:     "moved",
+			units:    []Unit{infantry},
+			tick:     Tick{Moved: []UnitPosition{{Username: "alice", Unit: moved}}},
+			reported: true,
+			want:     map[int]Unit{1: moved},
+		},
+		{
+			name:     "killed",
+			units:    []Unit{infantry},
+			tick:     Tick{Killed: []UnitPosition{{Username: "alice", Unit: infantry}}},
+			reported: true,
+			want:     map[int]Unit{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameState("alice")
+			for _, unit := range tt.units {
+				gs.addUnit(unit)
+			}
+			for _, unit := range tt.pending {
+				gs.queueSpawn(unit.Rank, unit.Location)
+			}
+
+			reported := gs.HandleTick(tt.tick)
+			if reported != tt.reported {
+				t.Errorf("reported = %v, want %v", reported, tt.reported)
+			}
+			if units := gs.GetPlayerSnap().Units; !reflect.DeepEqual(units, tt.want) {
+				t.Errorf("units = %v, want %v", units, tt.want)
+			}
+			if pending := gs.getPendingSpawns(); len(pending) != 0 {
+				t.Errorf("still waiting to deploy %v", pending)
+			}
+		})
+	}
+}
+
+func TestWorldTickBalances(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []UnitSpawned
+		spawns   []UnitSpawned
+		moves    []ArmyMove
+		balances map[string]int
+		refused  []RejectionReason
+	}{
+		{
+			name: "spawns are paid for and holdings earn income",
+			spawns: []UnitSpawned{
+				spawnOrder("alice", 1, RankInfantry, "americas"),
+				spawnOrder("bob", 1, RankCavalry, "europe"),
+			},
+			balances: map[string]int{"alice": 6, "bob": 3},
+		},
+		{
+			name:     "spawns the player can't afford are refused",
+			spawns:   []UnitSpawned{spawnOrder("alice", 1, RankArtillery, "americas")},
+			balances: map[string]int{"alice": 5},
+			refused:  []RejectionReason{RejectUnaffordable},
+		},
+		{
+			name: "contested locations earn nothing",
+			spawns: []UnitSpawned{
+				spawnOrder("alice", 1, RankInfantry, "americas"),
+				spawnOrder("bob", 1, RankInfantry, "americas"),
+			},
+			balances: map[string]int{"alice": 4, "bob": 4},
+		},
+		{
+			name:     "income comes from where units end the tick",
+			before:   []UnitSpawned{spawnOrder("alice", 1, RankInfantry, "americas")},
+			moves:    []ArmyMove{moveOrder("alice", "africa", 1)},
+			balances: map[string]int{"alice": 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tick := tickAfter(tt.before, tt.spawns, tt.moves).Tick
+			if !reflect.DeepEqual(tick.Balances, tt.balances) {
+				t.Errorf("balances = %v, want %v", tick.Balances, tt.balances)
+			}
+			checkRefused(t, tick.Refused, tt.refused)
+		})
+	}
+}
+
+func TestGameStateHandleTickBalance(t *testing.T) {
+	gs := NewGameState("alice")
+	reported := gs.HandleTick(Tick{Balances: map[string]int{"alice": 7, "bob": 2}})
+	if reported {
+		t.Error("a balance alone should not be reported")
+	}
+	if resources := gs.getResources(); resources != 7 {
+		t.Errorf("resources = %v, want 7", resources)
+	}
+}
+
+func TestWorldTickImpersonatedSpawns(t *testing.T) {
+	world := NewWorld()
+	world.QueueSpawn("mallory", spawnOrder("alice", 1, RankInfantry, "americas"))
+	tick := world.Tick(1).Tick
+
+	checkPositions(t, "spawned", tick.Spawned, nil)
+	checkRefused(t, tick.Refused, []RejectionReason{RejectImpersonation})
+	if len(tick.Refused) == 1 && tick.Refused[0].Username != "mallory" {
+		t.Errorf("refusal sent to %s, want the sender", tick.Refused[0].Username)
+	}
+	if _, ok := world.Player("alice"); ok {
+		t.Error("alice should not have joined the world")
+	}
+}
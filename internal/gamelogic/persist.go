@@ -0,0 +1,118 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+type gameStateSnapshot struct {
+	Username   string
+	Units      []Unit
+	Paused     bool
+	NextUnitID int
+	Resources  int
+}
+
+// StatePath is where a player's game state is saved inside dir.
+func StatePath(dir, username string) string {
+	return filepath.Join(dir, username+".json")
+}
+
+// AutoSave makes gs write itself to path after every change.
+func (gs *GameState) AutoSave(path string) error {
+	gs.mu.Lock()
+	gs.savePath = path
+	gs.mu.Unlock()
+	return gs.Save(path)
+}
+
+func (gs *GameState) changed() {
+	gs.mu.RLock()
+	path := gs.savePath
+	gs.mu.RUnlock()
+	if path == "" {
+		return
+	}
+	err := gs.Save(path)
+	if err != nil {
+		log.Printf("could not save game state: %v", err)
+	}
+}
+
+// Save writes a snapshot of gs to path. The snapshot is written to a
+// temporary file first so a crash never leaves a half-written state behind.
+func (gs *GameState) Save(path string) error {
+	gs.saveMu.Lock()
+	defer gs.saveMu.Unlock()
+
+	gs.mu.RLock()
+	snap := gameStateSnapshot{
+		Username:   gs.Player.Username,
+		Units:      []Unit{},
+		Paused:     gs.Paused,
+		NextUnitID: gs.NextUnitID,
+		Resources:  gs.Resources,
+	}
+	for _, unit := range gs.Player.Units {
+		snap.Units = append(snap.Units, unit)
+	}
+	gs.mu.RUnlock()
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode game state: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create state directory: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create state file: %w", err)
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	return nil
+}
+
+// LoadGameState restores the game state saved at path for username.
+func LoadGameState(path, username string) (*GameState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read state file: %w", err)
+	}
+
+	snap := gameStateSnapshot{}
+	err = json.Unmarshal(data, &snap)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode state file: %w", err)
+	}
+	if snap.Username != username {
+		return nil, fmt.Errorf("state file belongs to %s, not %s", snap.Username, username)
+	}
+
+	gs := NewGameState(username)
+	gs.Paused = snap.Paused
+	gs.NextUnitID = max(snap.NextUnitID, 1)
+	gs.Resources = snap.Resources
+	for _, unit := range snap.Units {
+		gs.Player.Units[unit.ID] = unit
+		gs.NextUnitID = max(gs.NextUnitID, unit.ID+1)
+	}
+	return gs, nil
+}
@@ -0,0 +1,53 @@
+package gamelogic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadGameState(t *testing.T) {
+	dir := t.TempDir()
+	path := StatePath(dir, "alice")
+
+	saved := NewGameState("alice")
+	saved.addUnit(Unit{ID: 1, Rank: RankInfantry, Location: "americas"})
+	saved.addUnit(Unit{ID: 4, Rank: RankCavalry, Location: "europe", Path: []Location{"asia"}})
+	saved.Paused = true
+	saved.Resources = 3
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	gs, err := LoadGameState(path, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if units := gs.GetPlayerSnap().Units; !reflect.DeepEqual(units, saved.GetPlayerSnap().Units) {
+		t.Errorf("units = %v, want %v", units, saved.GetPlayerSnap().Units)
+	}
+	if !gs.isPaused() {
+		t.Error("the game should still be paused")
+	}
+	if gs.Resources != 3 {
+		t.Errorf("resources = %v, want 3", gs.Resources)
+	}
+	if gs.NextUnitID != 5 {
+		t.Errorf("next unit ID = %v, want 5 so IDs don't clash", gs.NextUnitID)
+	}
+
+	if _, err := LoadGameState(path, "bob"); err == nil {
+		t.Error("loading alice's state as bob should fail")
+	}
+	if _, err := LoadGameState(filepath.Join(dir, "missing.json"), "alice"); err == nil {
+		t.Error("loading a missing state file should fail")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGameState(path, "alice"); err == nil {
+		t.Error("loading a corrupt state file should fail")
+	}
+}
@@ -0,0 +1,311 @@
+package gamelogic
+
+import (
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/perilpb"
+)
+
+func (mv ArmyMove) MarshalProto() ([]byte, error) {
+	units := []*perilpb.Unit{}
+	for _, unit := range mv.Units {
+		units = append(units, unitToProto(unit))
+	}
+	return proto.Marshal(&perilpb.ArmyMove{
+		Player:     playerToProto(mv.Player),
+		Units:      units,
+		ToLocation: string(mv.ToLocation),
+		Path:       locationsToProto(mv.Path),
+	})
+}
+
+func (mv *ArmyMove) UnmarshalProto(data []byte) error {
+	pb := &perilpb.ArmyMove{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	units := []Unit{}
+	for _, unit := range pb.GetUnits() {
+		units = append(units, unitFromProto(unit))
+	}
+	*mv = ArmyMove{
+		Player:     playerFromProto(pb.GetPlayer()),
+		Units:      units,
+		ToLocation: Location(pb.GetToLocation()),
+		Path:       locationsFromProto(pb.GetPath()),
+	}
+	return nil
+}
+
+func (t Tick) MarshalProto() ([]byte, error) {
+	return proto.Marshal(&perilpb.Tick{
+		Number:   t.Number,
+		Spawned:  positionsToProto(t.Spawned),
+		Moved:    positionsToProto(t.Moved),
+		Balances: balancesToProto(t.Balances),
+		Refused:  rejectionsToProto(t.Refused),
+		Killed:   positionsToProto(t.Killed),
+	})
+}
+
+func (t *Tick) UnmarshalProto(data []byte) error {
+	pb := &perilpb.Tick{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	*t = Tick{
+		Number:   pb.GetNumber(),
+		Spawned:  positionsFromProto(pb.GetSpawned()),
+		Moved:    positionsFromProto(pb.GetMoved()),
+		Balances: balancesFromProto(pb.GetBalances()),
+		Refused:  rejectionsFromProto(pb.GetRefused()),
+		Killed:   positionsFromProto(pb.GetKilled()),
+	}
+	return nil
+}
+
+// balancesToProto sorts balances by username so a tick always encodes the
+// same way.
+func balancesToProto(balances map[string]int) []*perilpb.Balance {
+	usernames := []string{}
+	for username := range balances {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+	pbs := []*perilpb.Balance{}
+	for _, username := range usernames {
+		pbs = append(pbs, &perilpb.Balance{
+			Username:  username,
+			Resources: int64(balances[username]),
+		})
+	}
+	return pbs
+}
+
+func balancesFromProto(pbs []*perilpb.Balance) map[string]int {
+	balances := map[string]int{}
+	for _, pb := range pbs {
+		balances[pb.GetUsername()] = int(pb.GetResources())
+	}
+	return balances
+}
+
+func positionsToProto(positions []UnitPosition) []*perilpb.UnitPosition {
+	pbs := []*perilpb.UnitPosition{}
+	for _, pos := range positions {
+		pbs = append(pbs, &perilpb.UnitPosition{
+			Username: pos.Username,
+			Unit:     unitToProto(pos.Unit),
+		})
+	}
+	return pbs
+}
+
+func positionsFromProto(pbs []*perilpb.UnitPosition) []UnitPosition {
+	positions := []UnitPosition{}
+	for _, pos := range pbs {
+		positions = append(positions, UnitPosition{
+			Username: pos.GetUsername(),
+			Unit:     unitFromProto(pos.GetUnit()),
+		})
+	}
+	return positions
+}
+
+func rejectionsToProto(rejections []SpawnRejection) []*perilpb.SpawnRejection {
+	pbs := []*perilpb.SpawnRejection{}
+	for _, sr := range rejections {
+		pbs = append(pbs, &perilpb.SpawnRejection{
+			Username: sr.Username,
+			Unit:     unitToProto(sr.Unit),
+			Reason:   string(sr.Reason),
+			Detail:   sr.Detail,
+		})
+	}
+	return pbs
+}
+
+func rejectionsFromProto(pbs []*perilpb.SpawnRejection) []SpawnRejection {
+	rejections := []SpawnRejection{}
+	for _, sr := range pbs {
+		rejections = append(rejections, SpawnRejection{
+			Username: sr.GetUsername(),
+			Unit:     unitFromProto(sr.GetUnit()),
+			Reason:   RejectionReason(sr.GetReason()),
+			Detail:   sr.GetDetail(),
+		})
+	}
+	return rejections
+}
+
+func (r Roster) MarshalProto() ([]byte, error) {
+	units := []*perilpb.Unit{}
+	for _, unit := range r.Units {
+		units = append(units, unitToProto(unit))
+	}
+	return proto.Marshal(&perilpb.Roster{
+		Username:  r.Username,
+		Units:     units,
+		Resources: int64(r.Resources),
+	})
+}
+
+func (r *Roster) UnmarshalProto(data []byte) error {
+	pb := &perilpb.Roster{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	units := []Unit{}
+	for _, unit := range pb.GetUnits() {
+		units = append(units, unitFromProto(unit))
+	}
+	*r = Roster{
+		Username:  pb.GetUsername(),
+		Units:     units,
+		Resources: int(pb.GetResources()),
+	}
+	return nil
+}
+
+func (rw RecognitionOfWar) MarshalProto() ([]byte, error) {
+	return proto.Marshal(&perilpb.RecognitionOfWar{
+		Attacker: playerToProto(rw.Attacker),
+		Defender: playerToProto(rw.Defender),
+		Seed:     rw.Seed,
+	})
+}
+
+func (rw *RecognitionOfWar) UnmarshalProto(data []byte) error {
+	pb := &perilpb.RecognitionOfWar{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	*rw = RecognitionOfWar{
+		Attacker: playerFromProto(pb.GetAttacker()),
+		Defender: playerFromProto(pb.GetDefender()),
+		Seed:     pb.GetSeed(),
+	}
+	return nil
+}
+
+func (us UnitSpawned) MarshalProto() ([]byte, error) {
+	return proto.Marshal(&perilpb.UnitSpawned{
+		Username: us.Username,
+		Unit:     unitToProto(us.Unit),
+	})
+}
+
+func (us *UnitSpawned) UnmarshalProto(data []byte) error {
+	pb := &perilpb.UnitSpawned{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	*us = UnitSpawned{
+		Username: pb.GetUsername(),
+		Unit:     unitFromProto(pb.GetUnit()),
+	}
+	return nil
+}
+
+func (mr MoveRejection) MarshalProto() ([]byte, error) {
+	unitIDs := []int64{}
+	for _, id := range mr.UnitIDs {
+		unitIDs = append(unitIDs, int64(id))
+	}
+	return proto.Marshal(&perilpb.MoveRejection{
+		Username:   mr.Username,
+		Reason:     string(mr.Reason),
+		Detail:     mr.Detail,
+		ToLocation: string(mr.ToLocation),
+		UnitIds:    unitIDs,
+	})
+}
+
+func (mr *MoveRejection) UnmarshalProto(data []byte) error {
+	pb := &perilpb.MoveRejection{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	unitIDs := []int{}
+	for _, id := range pb.GetUnitIds() {
+		unitIDs = append(unitIDs, int(id))
+	}
+	*mr = MoveRejection{
+		Username:   pb.GetUsername(),
+		Reason:     RejectionReason(pb.GetReason()),
+		Detail:     pb.GetDetail(),
+		ToLocation: Location(pb.GetToLocation()),
+		UnitIDs:    unitIDs,
+	}
+	return nil
+}
+
+func playerToProto(p Player) *perilpb.Player {
+	units := []*perilpb.Unit{}
+	for _, unit := range p.Units {
+		units = append(units, unitToProto(unit))
+	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Id < units[j].Id
+	})
+	return &perilpb.Player{
+		Username: p.Username,
+		Units:    units,
+	}
+}
+
+func playerFromProto(pb *perilpb.Player) Player {
+	units := map[int]Unit{}
+	for _, unit := range pb.GetUnits() {
+		units[int(unit.GetId())] = unitFromProto(unit)
+	}
+	return Player{
+		Username: pb.GetUsername(),
+		Units:    units,
+	}
+}
+
+func unitToProto(u Unit) *perilpb.Unit {
+	return &perilpb.Unit{
+		Id:       int64(u.ID),
+		Rank:     string(u.Rank),
+		Location: string(u.Location),
+		Path:     locationsToProto(u.Path),
+	}
+}
+
+func unitFromProto(pb *perilpb.Unit) Unit {
+	return Unit{
+		ID:       int(pb.GetId()),
+		Rank:     UnitRank(pb.GetRank()),
+		Location: Location(pb.GetLocation()),
+		Path:     locationsFromProto(pb.GetPath()),
+	}
+}
+
+func locationsToProto(locations []Location) []string {
+	names := []string{}
+	for _, loc := range locations {
+		names = append(names, string(loc))
+	}
+	return names
+}
+
+func locationsFromProto(names []string) []Location {
+	if len(names) == 0 {
+		return nil
+	}
+	locations := []Location{}
+	for _, name := range names {
+		locations = append(locations, Location(name))
+	}
+	return locations
+}
@@ -0,0 +1,69 @@
+package gamelogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+type protoMessage interface {
+	MarshalProto() ([]byte, error)
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	infantry := Unit{ID: 1, Rank: RankInfantry, Location: "americas"}
+	marching := Unit{ID: 2, Rank: RankCavalry, Location: "americas|europe", Path: []Location{"europe", "asia"}}
+
+	tests := []struct {
+		name string
+		msg  protoMessage
+		// decoded is a pointer to a zero value of msg's type.
+		decoded interface{ UnmarshalProto([]byte) error }
+	}{
+		{
+			name: "tick",
+			msg: Tick{
+				Number:   7,
+				Spawned:  []UnitPosition{{Username: "alice", Unit: infantry}},
+				Moved:    []UnitPosition{{Username: "bob", Unit: marching}},
+				Balances: map[string]int{"alice": 6, "bob": 0},
+				Refused: []SpawnRejection{{
+					Username: "bob",
+					Unit:     Unit{ID: 3, Rank: RankArtillery, Location: "asia"},
+					Reason:   RejectUnaffordable,
+					Detail:   "artillery costs 8 but bob has 0",
+				}},
+				Killed: []UnitPosition{{Username: "alice", Unit: infantry}},
+			},
+			decoded: &Tick{},
+		},
+		{
+			name: "army move",
+			msg: ArmyMove{
+				Player: Player{
+					Username: "bob",
+					Units:    map[int]Unit{1: infantry, 2: marching},
+				},
+				Units:      []Unit{marching},
+				ToLocation: "asia",
+				Path:       []Location{"europe", "asia"},
+			},
+			decoded: &ArmyMove{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.msg.MarshalProto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.decoded.UnmarshalProto(data); err != nil {
+				t.Fatal(err)
+			}
+			got := reflect.ValueOf(tt.decoded).Elem().Interface()
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("decoded %+v, want %+v", got, tt.msg)
+			}
+		})
+	}
+}
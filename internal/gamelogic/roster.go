@@ -0,0 +1,39 @@
+package gamelogic
+
+import "fmt"
+
+// HandleRoster replaces the player's units and resources with the ones the
+// server says they have. Units saved by an earlier game that the world no
+// longer has died while the player was away. Units still waiting to be
+// deployed are left alone: the next tick deploys or refuses them.
+func (gs *GameState) HandleRoster(r Roster) {
+	username := gs.GetUsername()
+	if r.Username != username {
+		return
+	}
+
+	gs.mu.Lock()
+	units := map[int]Unit{}
+	for _, unit := range r.Units {
+		units[unit.ID] = unit
+		gs.NextUnitID = max(gs.NextUnitID, unit.ID+1)
+	}
+	lost := []Unit{}
+	for id, unit := range gs.Player.Units {
+		if _, ok := units[id]; !ok {
+			lost = append(lost, unit)
+		}
+	}
+	gs.Player.Units = units
+	gs.Resources = r.Resources
+	gs.mu.Unlock()
+	gs.changed()
+
+	defer fmt.Println("------------------------")
+	fmt.Println()
+	fmt.Println("==== Roster ====")
+	fmt.Printf("The server says you have %v units and %v resources.\n", len(r.Units), r.Resources)
+	if len(lost) > 0 {
+		fmt.Printf("Units lost while you were away: %v\n", unitKeys(username, lost))
+	}
+}
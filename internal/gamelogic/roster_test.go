@@ -0,0 +1,44 @@
+package gamelogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHandleRoster(t *testing.T) {
+	world := NewWorld()
+	world.QueueSpawn("alice", spawnOrder("alice", 3, RankInfantry, "americas"))
+	world.QueueSpawn("alice", spawnOrder("alice", 1, RankInfantry, "europe"))
+	world.Tick(1)
+	roster := world.Roster("alice")
+
+	want := []Unit{
+		{ID: 1, Rank: RankInfantry, Location: "europe"},
+		{ID: 3, Rank: RankInfantry, Location: "americas"},
+	}
+	if !reflect.DeepEqual(roster.Units, want) {
+		t.Fatalf("roster units = %v, want %v", roster.Units, want)
+	}
+	if roster.Resources != 7 {
+		t.Errorf("roster resources = %v, want 7", roster.Resources)
+	}
+
+	gs := NewGameState("alice")
+	gs.addUnit(Unit{ID: 2, Rank: RankCavalry, Location: "asia"})
+	gs.HandleRoster(Roster{Username: "bob"})
+	if len(gs.GetPlayerSnap().Units) != 1 {
+		t.Error("another player's roster should be ignored")
+	}
+
+	gs.HandleRoster(roster)
+	units := gs.GetPlayerSnap().Units
+	if !reflect.DeepEqual(units, map[int]Unit{1: want[0], 3: want[1]}) {
+		t.Errorf("units = %v, want the roster's", units)
+	}
+	if gs.getResources() != 7 {
+		t.Errorf("resources = %v, want 7", gs.getResources())
+	}
+	if gs.NextUnitID != 4 {
+		t.Errorf("next unit ID = %v, want 4", gs.NextUnitID)
+	}
+}
@@ -0,0 +1,348 @@
+package gamelogic
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed scenarios/classic.yaml
+var classicScenario []byte
+
+// Scenario is the map and unit catalogue a game is played with. Scenarios
+// are read from YAML or JSON and sent to clients as JSON, and must be
+// validated before use; ParseScenario and LoadScenario do that. Every player
+// starts with StartingResources to spend on units. Combat names the combat
+// resolver wars are fought with; the server fills it in before sending the
+// scenario to clients, so they fight the same way it does.
+type Scenario struct {
+	Name              string                          `json:"name" yaml:"name"`
+	Combat            string                          `json:"combat,omitempty" yaml:"-"`
+	StartingResources int                             `json:"starting_resources" yaml:"starting_resources"`
+	Terrain           map[string]map[UnitRank]float64 `json:"terrain" yaml:"terrain"`
+	Locations         []LocationSpec                  `json:"locations" yaml:"locations"`
+	Units             []UnitSpec                      `json:"units" yaml:"units"`
+
+	locations map[Location]LocationSpec
+	adjacent  map[Location]map[Location]struct{}
+	units     map[UnitRank]UnitSpec
+}
+
+// LocationSpec describes a location. Income is what holding it earns a
+// player every tick.
+type LocationSpec struct {
+	Name     Location   `json:"name" yaml:"name"`
+	Terrain  string     `json:"terrain" yaml:"terrain"`
+	Income   int        `json:"income" yaml:"income"`
+	Adjacent []Location `json:"adjacent" yaml:"adjacent"`
+}
+
+// UnitSpec describes a rank. Cost is what spawning one takes and Speed how
+// many locations it can cross per tick.
+type UnitSpec struct {
+	Rank  UnitRank `json:"rank" yaml:"rank"`
+	Power float64  `json:"power" yaml:"power"`
+	Cost  int      `json:"cost" yaml:"cost"`
+	Speed int      `json:"speed" yaml:"speed"`
+}
+
+var defaultScenario = func() *Scenario {
+	s, err := ParseScenario(classicScenario)
+	if err != nil {
+		panic(fmt.Sprintf("built-in scenario is invalid: %v", err))
+	}
+	return s
+}()
+
+// DefaultScenario is the built-in classic map, used until a client hears
+// which scenario the server is running.
+func DefaultScenario() *Scenario {
+	return defaultScenario
+}
+
+// ParseScenario reads a scenario from YAML or JSON and validates it.
+func ParseScenario(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	err := yaml.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse scenario: %w", err)
+	}
+	err = s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read scenario: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// HandleScenario switches the player to the scenario the server is running,
+// and to the combat resolver it fights with.
+func (gs *GameState) HandleScenario(s *Scenario) {
+	defer fmt.Println("------------------------")
+	fmt.Println()
+	fmt.Printf("==== Playing Scenario %s ====\n", s.Name)
+	fmt.Printf("Locations: %v\n", s.LocationNames())
+	fmt.Printf("Starting resources: %v\n", s.StartingResources)
+	if s.Combat != "" {
+		fmt.Printf("Combat: %s\n", s.Combat)
+		r, _ := LookupCombatResolver(s.Combat)
+		gs.SetCombatResolver(r)
+	}
+	for _, rank := range s.Ranks() {
+		unit, _ := s.Unit(rank)
+		fmt.Printf("* %s: power %v, cost %v, speed %v\n", rank, unit.Power, unit.Cost, unit.Speed)
+	}
+	gs.SetScenario(s)
+}
+
+// Validate checks the scenario is self-consistent and indexes it. Borders
+// listed from either side are adjacent both ways, and a unit without a
+// speed moves one location per tick.
+func (s *Scenario) Validate() error {
+	if s.Name == "" {
+		return errors.New("invalid scenario: no name")
+	}
+	if len(s.Locations) == 0 {
+		return fmt.Errorf("invalid scenario %s: no locations", s.Name)
+	}
+	if len(s.Units) == 0 {
+		return fmt.Errorf("invalid scenario %s: no units", s.Name)
+	}
+	if s.StartingResources < 0 {
+		return fmt.Errorf("invalid scenario %s: negative starting resources", s.Name)
+	}
+	if s.Combat != "" {
+		_, err := LookupCombatResolver(s.Combat)
+		if err != nil {
+			return fmt.Errorf("invalid scenario %s: %w", s.Name, err)
+		}
+	}
+
+	units := map[UnitRank]UnitSpec{}
+	for i, unit := range s.Units {
+		if unit.Rank == "" {
+			return fmt.Errorf("invalid scenario %s: unit %v has no rank", s.Name, i+1)
+		}
+		if _, ok := units[unit.Rank]; ok {
+			return fmt.Errorf("invalid scenario %s: unit %s is listed twice", s.Name, unit.Rank)
+		}
+		if unit.Power <= 0 || unit.Cost < 0 || unit.Speed < 0 {
+			return fmt.Errorf("invalid scenario %s: unit %s needs a positive power and no negative cost or speed", s.Name, unit.Rank)
+		}
+		if unit.Speed == 0 {
+			unit.Speed = 1
+			s.Units[i] = unit
+		}
+		units[unit.Rank] = unit
+	}
+
+	for terrain, modifiers := range s.Terrain {
+		for rank, modifier := range modifiers {
+			if _, ok := units[rank]; !ok {
+				return fmt.Errorf("invalid scenario %s: terrain %s modifies unknown unit %s", s.Name, terrain, rank)
+			}
+			if modifier <= 0 {
+				return fmt.Errorf("invalid scenario %s: terrain %s needs a positive modifier for %s", s.Name, terrain, rank)
+			}
+		}
+	}
+
+	locations := map[Location]LocationSpec{}
+	for i, loc := range s.Locations {
+		if loc.Name == "" {
+			return fmt.Errorf("invalid scenario %s: location %v has no name", s.Name, i+1)
+		}
+		if _, ok := locations[loc.Name]; ok {
+			return fmt.Errorf("invalid scenario %s: location %s is listed twice", s.Name, loc.Name)
+		}
+		if IsBorder(loc.Name) {
+			return fmt.Errorf("invalid scenario %s: location %s can't contain |", s.Name, loc.Name)
+		}
+		if loc.Income < 0 {
+			return fmt.Errorf("invalid scenario %s: location %s has negative income", s.Name, loc.Name)
+		}
+		if _, ok := s.Terrain[loc.Terrain]; loc.Terrain != "" && !ok {
+			return fmt.Errorf("invalid scenario %s: location %s has unknown terrain %s", s.Name, loc.Name, loc.Terrain)
+		}
+		locations[loc.Name] = loc
+	}
+
+	adjacent := map[Location]map[Location]struct{}{}
+	for _, loc := range s.Locations {
+		for _, other := range loc.Adjacent {
+			if _, ok := locations[other]; !ok {
+				return fmt.Errorf("invalid scenario %s: %s borders unknown location %s", s.Name, loc.Name, other)
+			}
+			if other == loc.Name {
+				return fmt.Errorf("invalid scenario %s: %s borders itself", s.Name, loc.Name)
+			}
+			if adjacent[loc.Name] == nil {
+				adjacent[loc.Name] = map[Location]struct{}{}
+			}
+			if adjacent[other] == nil {
+				adjacent[other] = map[Location]struct{}{}
+			}
+			adjacent[loc.Name][other] = struct{}{}
+			adjacent[other][loc.Name] = struct{}{}
+		}
+	}
+
+	s.units = units
+	s.locations = locations
+	s.adjacent = adjacent
+	return nil
+}
+
+// WithCombat returns a copy of the scenario fought with the named combat
+// resolver.
+func (s *Scenario) WithCombat(name string) *Scenario {
+	withCombat := *s
+	withCombat.Combat = name
+	return &withCombat
+}
+
+func (s *Scenario) HasLocation(loc Location) bool {
+	_, ok := s.locations[loc]
+	return ok
+}
+
+func (s *Scenario) Unit(rank UnitRank) (UnitSpec, bool) {
+	unit, ok := s.units[rank]
+	return unit, ok
+}
+
+// Income is what holding loc earns every tick. Borders earn nothing.
+func (s *Scenario) Income(loc Location) int {
+	return s.locations[loc].Income
+}
+
+// Adjacent reports whether a and b share a border.
+func (s *Scenario) Adjacent(a, b Location) bool {
+	_, ok := s.adjacent[a][b]
+	return ok
+}
+
+// Neighbours returns the locations bordering loc, in a stable order.
+func (s *Scenario) Neighbours(loc Location) []Location {
+	neighbours := []Location{}
+	for other := range s.adjacent[loc] {
+		neighbours = append(neighbours, other)
+	}
+	sortLocations(neighbours)
+	return neighbours
+}
+
+// ShortestPath returns the fewest borders to cross to get from one location
+// to another: every location on the way, ending with to. Ties are broken
+// alphabetically, so everyone finds the same path.
+func (s *Scenario) ShortestPath(from, to Location) ([]Location, bool) {
+	if !s.HasLocation(from) || !s.HasLocation(to) || from == to {
+		return nil, false
+	}
+	previous := map[Location]Location{from: ""}
+	queue := []Location{from}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		for _, next := range s.Neighbours(loc) {
+			if _, ok := previous[next]; ok {
+				continue
+			}
+			previous[next] = loc
+			if next == to {
+				path := []Location{}
+				for at := to; at != from; at = previous[at] {
+					path = append([]Location{at}, path...)
+				}
+				return path, true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil, false
+}
+
+// ValidPath reports whether path is a walk along borders starting next to
+// from.
+func (s *Scenario) ValidPath(from Location, path []Location) bool {
+	if len(path) == 0 {
+		return false
+	}
+	at := from
+	for _, next := range path {
+		if !s.Adjacent(at, next) {
+			return false
+		}
+		at = next
+	}
+	return true
+}
+
+// TravelTicks is how many ticks a unit of rank takes to walk path.
+func (s *Scenario) TravelTicks(rank UnitRank, path []Location) int {
+	speed := max(s.units[rank].Speed, 1)
+	return (len(path) + speed - 1) / speed
+}
+
+// BorderLocation is where units fighting over the border between a and b
+// are. Border locations have no terrain.
+func BorderLocation(a, b Location) Location {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "|" + b
+}
+
+// IsBorder reports whether loc is a border rather than a map location.
+func IsBorder(loc Location) bool {
+	return strings.Contains(string(loc), "|")
+}
+
+// LocationNames and Ranks list every location and unit rank, in a stable
+// order.
+func (s *Scenario) LocationNames() []Location {
+	locations := []Location{}
+	for loc := range s.locations {
+		locations = append(locations, loc)
+	}
+	sortLocations(locations)
+	return locations
+}
+
+func (s *Scenario) Ranks() []UnitRank {
+	ranks := []UnitRank{}
+	for rank := range s.units {
+		ranks = append(ranks, rank)
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		return ranks[i] < ranks[j]
+	})
+	return ranks
+}
+
+// power is how strong unit is fighting in loc.
+func (s *Scenario) power(loc Location, unit Unit) float64 {
+	modifier, ok := s.Terrain[s.locations[loc].Terrain][unit.Rank]
+	if !ok {
+		modifier = 1
+	}
+	return s.units[unit.Rank].Power * modifier
+}
+
+func sortLocations(locations []Location) {
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i] < locations[j]
+	})
+}
@@ -0,0 +1,184 @@
+package gamelogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	tests := []struct {
+		name string
+		from Location
+		to   Location
+		want []Location
+		ok   bool
+	}{
+		{
+			name: "neighbours",
+			from: "americas",
+			to:   "europe",
+			want: []Location{"europe"},
+			ok:   true,
+		},
+		{
+			name: "ties broken alphabetically",
+			from: "americas",
+			to:   "australia",
+			want: []Location{"antarctica", "australia"},
+			ok:   true,
+		},
+		{
+			name: "only one shortest path",
+			from: "europe",
+			to:   "australia",
+			want: []Location{"asia", "australia"},
+			ok:   true,
+		},
+		{
+			name: "same location",
+			from: "asia",
+			to:   "asia",
+		},
+		{
+			name: "unknown location",
+			from: "americas",
+			to:   "atlantis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := DefaultScenario().ShortestPath(tt.from, tt.to)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(path, tt.want) {
+				t.Errorf("path = %v, want %v", path, tt.want)
+			}
+		})
+	}
+}
+
+func TestScenarioValidate(t *testing.T) {
+	valid := func() *Scenario {
+		return &Scenario{
+			Name: "test",
+			Terrain: map[string]map[UnitRank]float64{
+				"ice": {RankInfantry: 0.5},
+			},
+			Locations: []LocationSpec{
+				{Name: "north", Terrain: "ice", Adjacent: []Location{"south"}},
+				{Name: "south"},
+			},
+			Units: []UnitSpec{
+				{Rank: RankInfantry, Power: 1, Cost: 1},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *Scenario)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(s *Scenario) {},
+		},
+		{
+			name:    "no name",
+			modify:  func(s *Scenario) { s.Name = "" },
+			wantErr: true,
+		},
+		{
+			name:    "no locations",
+			modify:  func(s *Scenario) { s.Locations = nil },
+			wantErr: true,
+		},
+		{
+			name:    "no units",
+			modify:  func(s *Scenario) { s.Units = nil },
+			wantErr: true,
+		},
+		{
+			name:    "negative starting resources",
+			modify:  func(s *Scenario) { s.StartingResources = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "unit listed twice",
+			modify:  func(s *Scenario) { s.Units = append(s.Units, s.Units[0]) },
+			wantErr: true,
+		},
+		{
+			name:    "unit without power",
+			modify:  func(s *Scenario) { s.Units[0].Power = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "terrain modifies unknown unit",
+			modify:  func(s *Scenario) { s.Terrain["ice"][RankCavalry] = 0.5 },
+			wantErr: true,
+		},
+		{
+			name:    "unknown combat resolver",
+			modify:  func(s *Scenario) { s.Combat = "chess" },
+			wantErr: true,
+		},
+		{
+			name:    "zero terrain modifier",
+			modify:  func(s *Scenario) { s.Terrain["ice"][RankInfantry] = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "location listed twice",
+			modify:  func(s *Scenario) { s.Locations[1].Name = "north" },
+			wantErr: true,
+		},
+		{
+			name:    "location named like a border",
+			modify:  func(s *Scenario) { s.Locations[1].Name = "north|south" },
+			wantErr: true,
+		},
+		{
+			name:    "negative income",
+			modify:  func(s *Scenario) { s.Locations[0].Income = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "unknown terrain",
+			modify:  func(s *Scenario) { s.Locations[1].Terrain = "swamp" },
+			wantErr: true,
+		},
+		{
+			name:    "border to unknown location",
+			modify:  func(s *Scenario) { s.Locations[1].Adjacent = []Location{"east"} },
+			wantErr: true,
+		},
+		{
+			name:    "location borders itself",
+			modify:  func(s *Scenario) { s.Locations[1].Adjacent = []Location{"south"} },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(s)
+			err := s.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !s.Adjacent("south", "north") {
+				t.Error("borders should go both ways")
+			}
+			if spec, _ := s.Unit(RankInfantry); spec.Speed != 1 {
+				t.Errorf("speed = %v, want the default of 1", spec.Speed)
+			}
+		})
+	}
+}
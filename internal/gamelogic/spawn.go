@@ -5,30 +5,31 @@ import (
 	"fmt"
 )
 
-func (gs *GameState) CommandSpawn(words []string) error {
+func (gs *GameState) CommandSpawn(words []string) (Unit, error) {
 	if len(words) < 3 {
-		return errors.New("usage: spawn <location> <rank>")
+		return Unit{}, errors.New("usage: spawn <location> <rank>")
 	}
 
+	scenario := gs.Scenario()
 	locationName := words[1]
-	locations := getAllLocations()
-	if _, ok := locations[Location(locationName)]; !ok {
-		return fmt.Errorf("error: %s is not a valid location", locationName)
+	if !scenario.HasLocation(Location(locationName)) {
+		return Unit{}, fmt.Errorf("error: %s is not a valid location", locationName)
 	}
 
 	rank := words[2]
-	units := getAllRanks()
-	if _, ok := units[UnitRank(rank)]; !ok {
-		return fmt.Errorf("error: %s is not a valid unit", rank)
+	spec, ok := scenario.Unit(UnitRank(rank))
+	if !ok {
+		return Unit{}, fmt.Errorf("error: %s is not a valid unit", rank)
 	}
 
-	id := len(gs.getUnitsSnap()) + 1
-	gs.addUnit(Unit{
-		ID:       id,
-		Rank:     UnitRank(rank),
-		Location: Location(locationName),
-	})
+	available := gs.availableResources()
+	if available < spec.Cost {
+		return Unit{}, fmt.Errorf("error: a(n) %s costs %v, but you only have %v resources to spend", rank, spec.Cost, available)
+	}
+
+	unit := gs.queueSpawn(UnitRank(rank), Location(locationName))
 
-	fmt.Printf("Spawned a(n) %s in %s with id %v\n", rank, locationName, id)
-	return nil
+	fmt.Printf("Spawning a(n) %s in %s on the next tick for %v resources, with id %v (%s)\n",
+		rank, locationName, spec.Cost, unit.ID, NewUnitKey(gs.GetUsername(), unit.ID))
+	return unit, nil
 }
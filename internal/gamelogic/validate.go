@@ -0,0 +1,132 @@
+package gamelogic
+
+import "fmt"
+
+type RejectionReason string
+
+const (
+	RejectPaused          RejectionReason = "paused"
+	RejectImpersonation   RejectionReason = "impersonation"
+	RejectInvalidLocation RejectionReason = "invalid_location"
+	RejectUnknownPlayer   RejectionReason = "unknown_player"
+	RejectNoUnits         RejectionReason = "no_units"
+	RejectUnknownUnit     RejectionReason = "unknown_unit"
+	RejectForgedUnit      RejectionReason = "forged_unit"
+	RejectInvalidPath     RejectionReason = "invalid_path"
+	RejectInvalidUnit     RejectionReason = "invalid_unit"
+	RejectUnitExists      RejectionReason = "unit_exists"
+	RejectUnaffordable    RejectionReason = "unaffordable"
+)
+
+// MoveRejection is sent back to a player whose move the server refused.
+type MoveRejection struct {
+	Username   string
+	Reason     RejectionReason
+	Detail     string
+	ToLocation Location
+	UnitIDs    []int
+}
+
+// SpawnRejection tells a player, in the tick that would have deployed it,
+// that the server refused a unit they spawned.
+type SpawnRejection struct {
+	Username string
+	Unit     Unit
+	Reason   RejectionReason
+	Detail   string
+}
+
+type ValidationError struct {
+	Reason RejectionReason
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Detail)
+}
+
+func reject(reason RejectionReason, format string, args ...any) *ValidationError {
+	return &ValidationError{
+		Reason: reason,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+// validateMoveLocked checks mv, sent by sender, against the roster and the
+// map, and returns the path the army will take. Nothing in mv is trusted:
+// the units it moves must match units the world knows the player has, and
+// the path must follow borders from where the units really are. The player
+// snapshot it carries is ignored, as the client's roster may be stale. Moves
+// without a path, from clients older than ArmyMove version 2, take the
+// shortest one.
+func (w *World) validateMoveLocked(sender string, mv ArmyMove) ([]Location, error) {
+	if w.paused {
+		return nil, reject(RejectPaused, "the game is paused")
+	}
+	if mv.Player.Username != sender {
+		return nil, reject(RejectImpersonation, "%s sent a move for %s", sender, mv.Player.Username)
+	}
+	if !w.scenario.HasLocation(mv.ToLocation) {
+		return nil, reject(RejectInvalidLocation, "%s is not a valid location", mv.ToLocation)
+	}
+
+	roster, ok := w.players[sender]
+	if !ok {
+		return nil, reject(RejectUnknownPlayer, "%s has never spawned a unit", sender)
+	}
+	if len(mv.Units) == 0 {
+		return nil, reject(RejectNoUnits, "the move has no units")
+	}
+
+	seen := map[int]struct{}{}
+	for _, claimed := range mv.Units {
+		if _, ok := seen[claimed.ID]; ok {
+			return nil, reject(RejectForgedUnit, "unit %s is moved twice", NewUnitKey(sender, claimed.ID))
+		}
+		seen[claimed.ID] = struct{}{}
+		err := checkUnit(sender, roster, claimed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from := roster[mv.Units[0].ID].Location
+	for _, claimed := range mv.Units {
+		unit := roster[claimed.ID]
+		if IsBorder(unit.Location) {
+			return nil, reject(RejectInvalidPath, "unit %s is fighting at %s", NewUnitKey(sender, unit.ID), unit.Location)
+		}
+		if unit.Location != from {
+			return nil, reject(RejectInvalidPath, "the units are not all in %s", from)
+		}
+	}
+	if len(mv.Path) == 0 {
+		path, ok := w.scenario.ShortestPath(from, mv.ToLocation)
+		if !ok {
+			return nil, reject(RejectInvalidPath, "there is no way from %s to %s", from, mv.ToLocation)
+		}
+		return path, nil
+	}
+	if mv.Path[len(mv.Path)-1] != mv.ToLocation {
+		return nil, reject(RejectInvalidPath, "the path does not end in %s", mv.ToLocation)
+	}
+	if !w.scenario.ValidPath(from, mv.Path) {
+		return nil, reject(RejectInvalidPath, "%v is not a path along borders from %s", mv.Path, from)
+	}
+	return mv.Path, nil
+}
+
+func checkUnit(username string, roster map[int]Unit, claimed Unit) error {
+	key := NewUnitKey(username, claimed.ID)
+	unit, ok := roster[claimed.ID]
+	if !ok {
+		return reject(RejectUnknownUnit, "there is no unit %s", key)
+	}
+	if unit.Rank != claimed.Rank {
+		return reject(
+			RejectForgedUnit,
+			"unit %s is %s, not %s", key, unit.Rank, claimed.Rank,
+		)
+	}
+	return nil
+}
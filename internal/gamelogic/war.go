@@ -2,6 +2,12 @@ package gamelogic
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
 )
 
 type WarOutcome int
@@ -14,95 +20,240 @@ const (
 	WarOutcomeDraw
 )
 
-func (gs *GameState) HandleWar(
-	rw RecognitionOfWar,
-) (outcome WarOutcome, winner string, loser string) {
+// Battle is the fight in one location two players both have units in.
+type Battle struct {
+	BattleResult
+	Location      Location
+	AttackerUnits []Unit
+	DefenderUnits []Unit
+}
+
+// War is a war fought in every location the attacker and defender share.
+// Outcome combines the battles from the attacker's point of view: whoever
+// won more battles won the war.
+type War struct {
+	Attacker string
+	Defender string
+	Outcome  WarOutcome
+	Battles  []Battle
+}
+
+// resolveWar fights a battle in each contested location, in a stable order,
+// drawing any randomness the resolver needs from seed.
+func resolveWar(
+	resolver CombatResolver,
+	scenario *Scenario,
+	seed int64,
+	attacker, defender Player,
+) War {
+	war := War{
+		Attacker: attacker.Username,
+		Defender: defender.Username,
+		Outcome:  WarOutcomeNoUnits,
+		Battles:  []Battle{},
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	won, lost := 0, 0
+	for _, loc := range getOverlappingLocations(attacker, defender) {
+		battle := Battle{
+			Location:      loc,
+			AttackerUnits: unitsInLocation(attacker, loc),
+			DefenderUnits: unitsInLocation(defender, loc),
+		}
+		battle.BattleResult = resolver.Fight(rng, scenario, loc, battle.AttackerUnits, battle.DefenderUnits)
+		switch battle.Outcome {
+		case WarOutcomeYouWon:
+			won++
+		case WarOutcomeOpponentWon:
+			lost++
+		}
+		war.Battles = append(war.Battles, battle)
+	}
+
+	switch {
+	case len(war.Battles) == 0:
+	case won > lost:
+		war.Outcome = WarOutcomeYouWon
+	case lost > won:
+		war.Outcome = WarOutcomeOpponentWon
+	default:
+		war.Outcome = WarOutcomeDraw
+	}
+	return war
+}
+
+// Winner and Loser name the two sides by the combined outcome. In a draw
+// Winner is the attacker.
+func (w War) Winner() string {
+	if w.Outcome == WarOutcomeOpponentWon {
+		return w.Defender
+	}
+	return w.Attacker
+}
+
+func (w War) Loser() string {
+	if w.Outcome == WarOutcomeOpponentWon {
+		return w.Attacker
+	}
+	return w.Defender
+}
+
+// GameLog describes the war, with a result for every battle.
+func (w War) GameLog(now time.Time) routing.GameLog {
+	msg := fmt.Sprintf("%s won against %s", w.Winner(), w.Loser())
+	if w.Outcome == WarOutcomeDraw {
+		msg = fmt.Sprintf("A war between %s and %s resulted in a draw.", w.Attacker, w.Defender)
+	}
+
+	battles := []routing.Battle{}
+	for _, b := range w.Battles {
+		winner := ""
+		switch b.Outcome {
+		case WarOutcomeYouWon:
+			winner = w.Attacker
+		case WarOutcomeOpponentWon:
+			winner = w.Defender
+		}
+		casualties := []string{}
+		for _, key := range unitKeys(w.Attacker, b.AttackerLosses) {
+			casualties = append(casualties, string(key))
+		}
+		for _, key := range unitKeys(w.Defender, b.DefenderLosses) {
+			casualties = append(casualties, string(key))
+		}
+		battles = append(battles, routing.Battle{
+			Location:      string(b.Location),
+			Winner:        winner,
+			AttackerPower: b.AttackerPower,
+			DefenderPower: b.DefenderPower,
+			Casualties:    casualties,
+		})
+	}
+
+	return routing.GameLog{
+		CurrentTime: now,
+		Message:     msg,
+		Username:    w.Winner(),
+		Battles:     battles,
+	}
+}
+
+// HandleWar shows the player a war the server fought them in. rw carries
+// the armies and seed the server fought it with, so fighting it again gives
+// the same battles. It changes nothing: the tick that fought the war says
+// which units died. The outcome is from the player's point of view.
+func (gs *GameState) HandleWar(rw RecognitionOfWar) (WarOutcome, War) {
 	defer fmt.Println("------------------------")
 	fmt.Println()
 	fmt.Println("==== War Declared ====")
 	fmt.Printf("%s has declared war on %s!\n", rw.Attacker.Username, rw.Defender.Username)
 
-	player := gs.GetPlayerSnap()
-
-	if player.Username == rw.Defender.Username {
-		fmt.Printf("%s, you published the war.\n", player.Username)
-		return WarOutcomeNotInvolved, "", ""
-	}
-
-	if player.Username != rw.Attacker.Username {
-		fmt.Printf("%s, you are not involved in this war.\n", player.Username)
-		return WarOutcomeNotInvolved, "", ""
+	username := gs.GetUsername()
+	if username != rw.Attacker.Username && username != rw.Defender.Username {
+		fmt.Printf("%s, you are not involved in this war.\n", username)
+		return WarOutcomeNotInvolved, War{}
 	}
 
-	overlappingLocation := getOverlappingLocation(rw.Attacker, rw.Defender)
-	if overlappingLocation == "" {
+	war := resolveWar(gs.combatResolver(), gs.Scenario(), rw.Seed, rw.Attacker, rw.Defender)
+	if len(war.Battles) == 0 {
 		fmt.Printf("Error! No units are in the same location. No war will be fought.\n")
-		return WarOutcomeNoUnits, "", ""
+		return WarOutcomeNoUnits, war
 	}
 
-	attackerUnits := []Unit{}
-	defenderUnits := []Unit{}
-	for _, unit := range rw.Attacker.Units {
-		if unit.Location == overlappingLocation {
-			attackerUnits = append(attackerUnits, unit)
+	for _, battle := range war.Battles {
+		fmt.Printf("-- Battle for %s --\n", battle.Location)
+		fmt.Printf("%s's units:\n", rw.Attacker.Username)
+		for _, unit := range battle.AttackerUnits {
+			fmt.Printf("  * %v (%s)\n", unit.Rank, NewUnitKey(rw.Attacker.Username, unit.ID))
 		}
-	}
-	for _, unit := range rw.Defender.Units {
-		if unit.Location == overlappingLocation {
-			defenderUnits = append(defenderUnits, unit)
+		fmt.Printf("%s's units:\n", rw.Defender.Username)
+		for _, unit := range battle.DefenderUnits {
+			fmt.Printf("  * %v (%s)\n", unit.Rank, NewUnitKey(rw.Defender.Username, unit.ID))
+		}
+		fmt.Printf("Attacker has a power level of %v\n", battle.AttackerPower)
+		fmt.Printf("Defender has a power level of %v\n", battle.DefenderPower)
+
+		switch battle.Outcome {
+		case WarOutcomeYouWon:
+			fmt.Printf("%s has won the battle for %s!\n", rw.Attacker.Username, battle.Location)
+		case WarOutcomeOpponentWon:
+			fmt.Printf("%s has won the battle for %s!\n", rw.Defender.Username, battle.Location)
+		default:
+			fmt.Printf("The battle for %s ended in a draw!\n", battle.Location)
 		}
-	}
 
-	fmt.Printf("%s's units:\n", rw.Attacker.Username)
-	for _, unit := range attackerUnits {
-		fmt.Printf("  * %v\n", unit.Rank)
-	}
-	fmt.Printf("%s's units:\n", rw.Defender.Username)
-	for _, unit := range defenderUnits {
-		fmt.Printf("  * %v\n", unit.Rank)
-	}
-	attackerPower := unitsToPowerLevel(attackerUnits)
-	defenderPower := unitsToPowerLevel(defenderUnits)
-	fmt.Printf("Attacker has a power level of %v\n", attackerPower)
-	fmt.Printf("Defender has a power level of %v\n", defenderPower)
-	if attackerPower > defenderPower {
-		fmt.Printf("%s has won the war!\n", rw.Attacker.Username)
-		if player.Username == rw.Defender.Username {
-			fmt.Println("You have lost the war!")
-			gs.removeUnitsInLocation(overlappingLocation)
-			fmt.Printf("Your units in %s have been killed.\n", overlappingLocation)
-			return WarOutcomeOpponentWon, rw.Attacker.Username, rw.Defender.Username
+		losses, enemy, enemyLosses := battle.AttackerLosses, rw.Defender.Username, battle.DefenderLosses
+		if username == rw.Defender.Username {
+			losses, enemy, enemyLosses = battle.DefenderLosses, rw.Attacker.Username, battle.AttackerLosses
 		}
-		return WarOutcomeYouWon, rw.Attacker.Username, rw.Defender.Username
-	} else if defenderPower > attackerPower {
-		fmt.Printf("%s has won the war!\n", rw.Defender.Username)
-		if player.Username == rw.Attacker.Username {
-			fmt.Println("You have lost the war!")
-			gs.removeUnitsInLocation(overlappingLocation)
-			fmt.Printf("Your units in %s have been killed.\n", overlappingLocation)
-			return WarOutcomeOpponentWon, rw.Defender.Username, rw.Attacker.Username
+		fmt.Printf("%s lost %v\n", enemy, unitKeys(enemy, enemyLosses))
+		if len(losses) > 0 {
+			fmt.Printf("Your units have been killed: %v\n", unitKeys(username, losses))
 		}
-		return WarOutcomeYouWon, rw.Defender.Username, rw.Attacker.Username
 	}
-	fmt.Println("The war ended in a draw!")
-	fmt.Printf("Your units in %s have been killed.\n", overlappingLocation)
-	gs.removeUnitsInLocation(overlappingLocation)
-	return WarOutcomeDraw, rw.Attacker.Username, rw.Defender.Username
-}
 
-func unitsToPowerLevel(units []Unit) int {
-	power := 0
-	for _, unit := range units {
-		if unit.Rank == RankArtillery {
-			power += 10
+	outcome := war.Outcome
+	if username == rw.Defender.Username {
+		switch outcome {
+		case WarOutcomeYouWon:
+			outcome = WarOutcomeOpponentWon
+		case WarOutcomeOpponentWon:
+			outcome = WarOutcomeYouWon
 		}
-		if unit.Rank == RankCavalry {
-			power += 5
+	}
+	switch outcome {
+	case WarOutcomeYouWon:
+		fmt.Println("You have won the war!")
+	case WarOutcomeOpponentWon:
+		fmt.Println("You have lost the war!")
+	default:
+		fmt.Println("The war ended in a draw!")
+	}
+	return outcome, war
+}
+
+// getOverlappingLocations returns every location both players have units
+// in, in a stable order.
+func getOverlappingLocations(p1 Player, p2 Player) []Location {
+	seen := map[Location]struct{}{}
+	for _, unit := range p1.Units {
+		seen[unit.Location] = struct{}{}
+	}
+	overlap := map[Location]struct{}{}
+	for _, unit := range p2.Units {
+		if _, ok := seen[unit.Location]; ok {
+			overlap[unit.Location] = struct{}{}
 		}
-		if unit.Rank == RankInfantry {
-			power += 1
+	}
+
+	locations := []Location{}
+	for loc := range overlap {
+		locations = append(locations, loc)
+	}
+	sortLocations(locations)
+	return locations
+}
+
+func unitsInLocation(p Player, loc Location) []Unit {
+	units := []Unit{}
+	for _, unit := range p.Units {
+		if unit.Location == loc {
+			units = append(units, unit)
 		}
 	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].ID < units[j].ID
+	})
+	return units
+}
 
-	return power
+// unitsToPowerLevel is the units' power before terrain modifiers.
+func unitsToPowerLevel(scenario *Scenario, units []Unit) int {
+	power := 0.0
+	for _, unit := range units {
+		spec, _ := scenario.Unit(unit.Rank)
+		power += spec.Power
+	}
+	return int(math.Round(power))
 }
@@ -0,0 +1,300 @@
+package gamelogic
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// World is the server's authoritative view of every player's units. Clients
+// tell it what they spawn and how they move, but a move is only accepted if
+// it matches what the world already knows about the player. Spawns and moves
+// are queued and all take effect together on the next tick, once the player
+// has paid for the units they spawn.
+type World struct {
+	mu        *sync.RWMutex
+	players   map[string]map[int]Unit
+	resources map[string]int
+	paused    bool
+	combat    CombatResolver
+	scenario  *Scenario
+
+	queuedSpawns []queuedSpawn
+	queuedMoves  []queuedMove
+}
+
+type queuedSpawn struct {
+	sender string
+	spawn  UnitSpawned
+}
+
+type queuedMove struct {
+	sender        string
+	move          ArmyMove
+	correlationID string
+}
+
+// MoveResult is what became of a queued move on the tick it was resolved.
+// Err is a *ValidationError if the move was rejected; otherwise Move is the
+// move as the world accepted it.
+type MoveResult struct {
+	Sender        string
+	Move          ArmyMove
+	CorrelationID string
+	Err           error
+}
+
+func NewWorld() *World {
+	return &World{
+		mu:        &sync.RWMutex{},
+		players:   map[string]map[int]Unit{},
+		resources: map[string]int{},
+		combat:    defaultCombatResolver(),
+		scenario:  DefaultScenario(),
+	}
+}
+
+func (w *World) SetScenario(s *Scenario) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.scenario = s
+}
+
+func (w *World) Scenario() *Scenario {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.scenario
+}
+
+// SetCombatResolver changes how the world fights wars. Clients must use the
+// same resolver, or their units will disagree with the world's.
+func (w *World) SetCombatResolver(r CombatResolver) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.combat = r
+}
+
+// QueueSpawn queues us, sent by sender, to be checked and paid for on the
+// next tick, which tells sender if it was refused.
+func (w *World) QueueSpawn(sender string, us UnitSpawned) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queuedSpawns = append(w.queuedSpawns, queuedSpawn{
+		sender: sender,
+		spawn:  us,
+	})
+}
+
+// QueueMove queues mv, sent by sender, to be validated and applied on the
+// next tick. correlationID is handed back in the move's MoveResult.
+func (w *World) QueueMove(sender string, mv ArmyMove, correlationID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queuedMoves = append(w.queuedMoves, queuedMove{
+		sender:        sender,
+		move:          mv,
+		correlationID: correlationID,
+	})
+}
+
+// spawnLocked checks us, sent by sender, charges the player for its unit
+// and adds it. A refused spawn returns a *ValidationError. IDs are never
+// reused, so a unit the world already has is refused rather than charged
+// for again. A new player starts with the scenario's starting resources.
+func (w *World) spawnLocked(sender string, us UnitSpawned) error {
+	if us.Username != sender {
+		return reject(RejectImpersonation, "%s sent a spawn for %s", sender, us.Username)
+	}
+	if !w.scenario.HasLocation(us.Unit.Location) {
+		return reject(RejectInvalidLocation, "%s is not a valid location", us.Unit.Location)
+	}
+	spec, ok := w.scenario.Unit(us.Unit.Rank)
+	if !ok {
+		return reject(RejectInvalidUnit, "%s is not a valid unit", us.Unit.Rank)
+	}
+	key := NewUnitKey(us.Username, us.Unit.ID)
+	if existing, ok := w.players[us.Username][us.Unit.ID]; ok {
+		return reject(RejectUnitExists, "unit %s already exists as %s", key, existing.Rank)
+	}
+
+	if _, ok := w.resources[us.Username]; !ok {
+		w.resources[us.Username] = w.scenario.StartingResources
+	}
+	if w.resources[us.Username] < spec.Cost {
+		return reject(
+			RejectUnaffordable,
+			"a(n) %s costs %v but %s has %v",
+			spec.Rank, spec.Cost, us.Username, w.resources[us.Username],
+		)
+	}
+	w.resources[us.Username] -= spec.Cost
+	if w.players[us.Username] == nil {
+		w.players[us.Username] = map[int]Unit{}
+	}
+	unit := us.Unit
+	unit.Path = nil
+	w.players[us.Username][unit.ID] = unit
+	return nil
+}
+
+// moveLocked validates mv, sent by sender, sets the army off along its path
+// and returns the move as the world sees it: the units and player snapshot
+// come from the world, not from mv. A rejected move returns a
+// *ValidationError.
+func (w *World) moveLocked(sender string, mv ArmyMove) (ArmyMove, error) {
+	path, err := w.validateMoveLocked(sender, mv)
+	if err != nil {
+		return ArmyMove{}, err
+	}
+
+	units := w.players[sender]
+	moved := []Unit{}
+	for _, claimed := range mv.Units {
+		unit := units[claimed.ID]
+		unit.Path = append([]Location{}, path...)
+		units[unit.ID] = unit
+		moved = append(moved, unit)
+	}
+
+	return ArmyMove{
+		Player:     w.playerLocked(sender),
+		Units:      moved,
+		ToLocation: mv.ToLocation,
+		Path:       path,
+	}, nil
+}
+
+func (w *World) SetPaused(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = paused
+}
+
+func (w *World) removeUnitsLocked(username string, units []Unit) {
+	for _, unit := range units {
+		delete(w.players[username], unit.ID)
+	}
+}
+
+func (w *World) playerLocked(username string) Player {
+	units := map[int]Unit{}
+	for k, v := range w.players[username] {
+		units[k] = v
+	}
+	return Player{
+		Username: username,
+		Units:    units,
+	}
+}
+
+func (w *World) Player(username string) (Player, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if _, ok := w.players[username]; !ok {
+		return Player{}, false
+	}
+	return w.playerLocked(username), true
+}
+
+// GameState returns the state username's client should have, according to
+// the world.
+func (w *World) GameState(username string) (*GameState, bool) {
+	player, ok := w.Player(username)
+	if !ok {
+		return nil, false
+	}
+	w.mu.RLock()
+	paused, scenario := w.paused, w.scenario
+	w.mu.RUnlock()
+
+	gs := NewGameState(username)
+	gs.Paused = paused
+	gs.Resources = w.Resources(username)
+	gs.scenario = scenario
+	for id, unit := range player.Units {
+		gs.Player.Units[id] = unit
+		gs.NextUnitID = max(gs.NextUnitID, id+1)
+	}
+	return gs, true
+}
+
+// Roster returns the units and resources the world says username has. A
+// player who has never spawned a unit has the scenario's starting
+// resources.
+func (w *World) Roster(username string) Roster {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	roster := Roster{
+		Username:  username,
+		Units:     []Unit{},
+		Resources: w.scenario.StartingResources,
+	}
+	if resources, ok := w.resources[username]; ok {
+		roster.Resources = resources
+	}
+	units := w.players[username]
+	for _, id := range sortedUnitIDs(units) {
+		roster.Units = append(roster.Units, units[id])
+	}
+	return roster
+}
+
+// Resources is what username has left to spend.
+func (w *World) Resources(username string) int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.resources[username]
+}
+
+// Occupancy returns, for every location with units in it, each player's
+// units there.
+func (w *World) Occupancy() map[Location]map[string][]Unit {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	occupancy := map[Location]map[string][]Unit{}
+	for username, units := range w.players {
+		for _, unit := range units {
+			if occupancy[unit.Location] == nil {
+				occupancy[unit.Location] = map[string][]Unit{}
+			}
+			occupancy[unit.Location][username] = append(occupancy[unit.Location][username], unit)
+		}
+	}
+	return occupancy
+}
+
+func (w *World) CommandStatus() {
+	occupancy := w.Occupancy()
+	scenario := w.Scenario()
+	w.mu.RLock()
+	usernames := w.usernamesLocked()
+	w.mu.RUnlock()
+	if len(usernames) > 0 {
+		fmt.Println("Resources:")
+		for _, username := range usernames {
+			fmt.Printf("  * %s: %v\n", username, w.Resources(username))
+		}
+	}
+	if len(occupancy) == 0 {
+		fmt.Println("The world is empty.")
+		return
+	}
+	locations := []Location{}
+	for loc := range occupancy {
+		locations = append(locations, loc)
+	}
+	sortLocations(locations)
+	for _, loc := range locations {
+		players := occupancy[loc]
+		fmt.Printf("%s:\n", loc)
+		usernames := []string{}
+		for username := range players {
+			usernames = append(usernames, username)
+		}
+		sort.Strings(usernames)
+		for _, username := range usernames {
+			fmt.Printf("  * %s: %v unit(s), power %v\n",
+				username, len(players[username]), unitsToPowerLevel(scenario, players[username]))
+		}
+	}
+}
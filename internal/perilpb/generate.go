@@ -0,0 +1,5 @@
+// Package perilpb holds the protobuf schemas for every message Peril puts on
+// the wire, for consumers that don't speak Go's gob.
+package perilpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative peril.proto
@@ -0,0 +1,143 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	ErrNacked         = errors.New("broker did not accept the message")
+	ErrConfirmTimeout = errors.New("timed out waiting for publish confirmation")
+)
+
+// UnroutableError is returned when the broker accepted a message but no queue
+// was bound to receive it.
+type UnroutableError struct {
+	Exchange   string
+	RoutingKey string
+	ReplyCode  uint16
+	ReplyText  string
+}
+
+func (e *UnroutableError) Error() string {
+	return fmt.Sprintf(
+		"message to exchange %v with key %v was not routed to any queue: %v",
+		e.Exchange, e.RoutingKey, e.ReplyText,
+	)
+}
+
+// ConfirmingPublisher publishes every message as mandatory on a channel in
+// confirm mode, and only returns once the broker has acked it. Publishes are
+// serialized, so it is safe to share between goroutines.
+type ConfirmingPublisher struct {
+	conn    *Conn
+	timeout time.Duration
+
+	mu       sync.Mutex
+	ch       *amqp.Channel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+}
+
+func NewConfirmingPublisher(conn *Conn, timeout time.Duration) *ConfirmingPublisher {
+	return &ConfirmingPublisher{
+		conn:    conn,
+		timeout: timeout,
+	}
+}
+
+func (p *ConfirmingPublisher) channel() (*amqp.Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, nil
+	}
+
+	ch, err := p.conn.openChannel()
+	if err != nil {
+		return nil, err
+	}
+	err = ch.Confirm(false)
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("could not put channel in confirm mode: %w", err)
+	}
+
+	p.ch = ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, 1))
+	return ch, nil
+}
+
+func (p *ConfirmingPublisher) reset() {
+	if p.ch != nil {
+		p.ch.Close()
+	}
+	p.ch = nil
+}
+
+// Publish ignores mandatory and always publishes as mandatory so that
+// unroutable messages come back as an *UnroutableError.
+func (p *ConfirmingPublisher) Publish(
+	exchange,
+	key string,
+	mandatory,
+	immediate bool,
+	msg amqp.Publishing,
+) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+
+	err = ch.Publish(exchange, key, true, immediate, msg)
+	if err != nil {
+		p.reset()
+		return err
+	}
+
+	select {
+	case confirm, ok := <-p.confirms:
+		if !ok {
+			p.reset()
+			return fmt.Errorf("channel closed before the message was confirmed")
+		}
+		// The broker sends basic.return before the ack for the same message,
+		// so any return is already waiting for us.
+		select {
+		case ret := <-p.returns:
+			return &UnroutableError{
+				Exchange:   ret.Exchange,
+				RoutingKey: ret.RoutingKey,
+				ReplyCode:  ret.ReplyCode,
+				ReplyText:  ret.ReplyText,
+			}
+		default:
+		}
+		if !confirm.Ack {
+			return ErrNacked
+		}
+		return nil
+	case <-time.After(p.timeout):
+		// A late confirmation would be mistaken for the next message's, so
+		// start over on a fresh channel.
+		p.reset()
+		return ErrConfirmTimeout
+	}
+}
+
+func (p *ConfirmingPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ch == nil {
+		return nil
+	}
+	err := p.ch.Close()
+	p.ch = nil
+	return err
+}
@@ -0,0 +1,190 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+var (
+	ErrClosed       = errors.New("connection is closed")
+	ErrNotConnected = errors.New("not connected to RabbitMQ")
+)
+
+// Conn wraps an AMQP connection and transparently redials it when the broker
+// goes away. Subscriptions made through a Conn redeclare their queues and
+// start consuming again once the connection is back.
+type Conn struct {
+	url string
+
+	mu     sync.Mutex
+	conn   *amqp.Connection
+	ready  chan struct{}
+	closed bool
+	done   chan struct{}
+}
+
+func Dial(url string) (*Conn, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to RabbitMQ: %w", err)
+	}
+
+	c := &Conn{
+		url:   url,
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	c.setConnection(conn)
+
+	return c, nil
+}
+
+func (c *Conn) setConnection(conn *amqp.Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+	close(c.ready)
+	go c.watch(conn)
+}
+
+func (c *Conn) watch(conn *amqp.Connection) {
+	closeErr := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+	c.ready = make(chan struct{})
+	c.mu.Unlock()
+
+	log.Printf("lost connection to RabbitMQ: %v", closeErr)
+	c.reconnect()
+}
+
+func (c *Conn) reconnect() {
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(delay):
+		}
+
+		conn, err := amqp.Dial(c.url)
+		if err != nil {
+			delay = nextReconnectDelay(delay)
+			log.Printf("could not reconnect to RabbitMQ, retrying in %v: %v", delay, err)
+			continue
+		}
+
+		c.mu.Lock()
+		closed := c.closed
+		c.mu.Unlock()
+		if closed {
+			conn.Close()
+			return
+		}
+
+		log.Println("reconnected to RabbitMQ")
+		c.setConnection(conn)
+		return
+	}
+}
+
+// connection blocks until a live connection is available or c is closed.
+func (c *Conn) connection() (*amqp.Connection, error) {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return nil, ErrClosed
+		}
+		conn, ready := c.conn, c.ready
+		c.mu.Unlock()
+
+		if conn != nil && !conn.IsClosed() {
+			return conn, nil
+		}
+
+		// The connection can be closed before watch has swapped in a fresh
+		// ready channel, so don't spin on the old one.
+		wait := ready
+		if conn != nil {
+			wait = nil
+		}
+		select {
+		case <-wait:
+		case <-time.After(minReconnectDelay):
+		case <-c.done:
+			return nil, ErrClosed
+		}
+	}
+}
+
+// Channel opens a new channel, waiting for a reconnect if necessary.
+func (c *Conn) Channel() (*amqp.Channel, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("could not create channel: %w", err)
+	}
+	return ch, nil
+}
+
+// openChannel opens a new channel without waiting for a reconnect.
+func (c *Conn) openChannel() (*amqp.Channel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.openChannelLocked()
+}
+
+func (c *Conn) openChannelLocked() (*amqp.Channel, error) {
+	if c.closed {
+		return nil, ErrClosed
+	}
+	if c.conn == nil || c.conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("could not create channel: %w", err)
+	}
+	return ch, nil
+}
+
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+func nextReconnectDelay(delay time.Duration) time.Duration {
+	return min(delay*2, maxReconnectDelay)
+}
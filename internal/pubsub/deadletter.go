@@ -0,0 +1,269 @@
+package pubsub
+
+import (
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	DeadLetterExchange = "peril_dlx"
+	DeadLetterQueue    = "peril_dlq"
+)
+
+// Headers set on messages that a subscriber dead-letters itself, rather than
+// through the queue's x-dead-letter-exchange. The original exchange and
+// routing key are also set on a message's first retry.
+const (
+	HeaderError              = "x-peril-error"
+	HeaderQueue              = "x-peril-queue"
+	HeaderOriginalExchange   = "x-peril-original-exchange"
+	HeaderOriginalRoutingKey = "x-peril-original-routing-key"
+)
+
+// DeclareDeadLetter declares the fanout exchange every queue dead-letters to
+// and the durable queue that collects those messages. It is idempotent.
+func DeclareDeadLetter(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
+		DeadLetterExchange,
+		amqp.ExchangeFanout,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("could not declare dead letter exchange: %w", err)
+	}
+
+	_, err = ch.QueueDeclare(DeadLetterQueue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not declare dead letter queue: %w", err)
+	}
+
+	err = ch.QueueBind(DeadLetterQueue, "", DeadLetterExchange, false, nil)
+	if err != nil {
+		return fmt.Errorf("could not bind dead letter queue: %w", err)
+	}
+
+	return nil
+}
+
+type Death struct {
+	Queue       string
+	Reason      string
+	Exchange    string
+	RoutingKeys []string
+	Count       int64
+	Time        time.Time
+}
+
+// DeadLetter is a message sitting in the dead letter queue. OriginalExchange
+// and OriginalRoutingKey are where it was first published, which is where a
+// replay sends it back to.
+type DeadLetter struct {
+	Position           int
+	MessageID          string
+	OriginalExchange   string
+	OriginalRoutingKey string
+	ContentType        string
+	Error              string
+	Body               []byte
+	Deaths             []Death
+}
+
+func newDeadLetter(position int, delivery amqp.Delivery) DeadLetter {
+	dl := DeadLetter{
+		Position:           position,
+		MessageID:          delivery.MessageId,
+		OriginalExchange:   delivery.Exchange,
+		OriginalRoutingKey: delivery.RoutingKey,
+		ContentType:        delivery.ContentType,
+		Body:               delivery.Body,
+		Deaths:             parseDeaths(delivery.Headers),
+	}
+	dl.Error, _ = delivery.Headers[HeaderError].(string)
+
+	// x-death lists the most recent death first.
+	if len(dl.Deaths) > 0 {
+		first := dl.Deaths[len(dl.Deaths)-1]
+		dl.OriginalExchange = first.Exchange
+		if len(first.RoutingKeys) > 0 {
+			dl.OriginalRoutingKey = first.RoutingKeys[0]
+		}
+	}
+	if exchange, ok := delivery.Headers[HeaderOriginalExchange].(string); ok {
+		dl.OriginalExchange = exchange
+	}
+	if key, ok := delivery.Headers[HeaderOriginalRoutingKey].(string); ok {
+		dl.OriginalRoutingKey = key
+	}
+
+	return dl
+}
+
+// publishDeadLetter sends a copy of delivery to the dead letter exchange with
+// cause recorded in its headers.
+func publishDeadLetter(
+	ch *amqp.Channel,
+	queueName string,
+	delivery amqp.Delivery,
+	cause error,
+) error {
+	headers := amqp.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+	headers[HeaderError] = cause.Error()
+	headers[HeaderQueue] = queueName
+	// A retried delivery already says where it was first published.
+	if _, ok := headers[HeaderOriginalExchange]; !ok {
+		headers[HeaderOriginalExchange] = delivery.Exchange
+		headers[HeaderOriginalRoutingKey] = delivery.RoutingKey
+	}
+
+	msg := republishing(delivery, headers)
+	msg.DeliveryMode = amqp.Persistent
+	err := ch.Publish(DeadLetterExchange, delivery.RoutingKey, false, false, msg)
+	if err != nil {
+		return fmt.Errorf("could not publish to dead letter exchange: %w", err)
+	}
+	return nil
+}
+
+func parseDeaths(headers amqp.Table) []Death {
+	entries, ok := headers["x-death"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	deaths := []Death{}
+	for _, entry := range entries {
+		table, ok := entry.(amqp.Table)
+		if !ok {
+			continue
+		}
+		death := Death{}
+		death.Queue, _ = table["queue"].(string)
+		death.Reason, _ = table["reason"].(string)
+		death.Exchange, _ = table["exchange"].(string)
+		death.Count, _ = table["count"].(int64)
+		death.Time, _ = table["time"].(time.Time)
+		keys, _ := table["routing-keys"].([]interface{})
+		for _, key := range keys {
+			if k, ok := key.(string); ok {
+				death.RoutingKeys = append(death.RoutingKeys, k)
+			}
+		}
+		deaths = append(deaths, death)
+	}
+	return deaths
+}
+
+// ListDeadLetters returns up to limit messages from the front of the dead
+// letter queue without removing them. Positions start at 1.
+func ListDeadLetters(conn *Conn, limit int) ([]DeadLetter, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	// Closing the channel requeues every message we fetched but did not ack.
+	defer ch.Close()
+
+	err = DeclareDeadLetter(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters := []DeadLetter{}
+	for i := 1; i <= limit; i++ {
+		delivery, ok, err := ch.Get(DeadLetterQueue, false)
+		if err != nil {
+			return nil, fmt.Errorf("could not get dead letter: %w", err)
+		}
+		if !ok {
+			break
+		}
+		deadLetters = append(deadLetters, newDeadLetter(i, delivery))
+	}
+
+	return deadLetters, nil
+}
+
+// replayHeaders drops the headers that record how a message died, and how
+// often it was retried, so a replayed message starts afresh.
+func replayHeaders(deadHeaders amqp.Table) amqp.Table {
+	headers := amqp.Table{}
+	for k, v := range deadHeaders {
+		switch k {
+		case "x-death", HeaderError, HeaderQueue, HeaderOriginalExchange, HeaderOriginalRoutingKey, HeaderAttempts:
+		default:
+			headers[k] = v
+		}
+	}
+	return headers
+}
+
+// ReplayDeadLetters republishes the dead letters at the given positions (as
+// reported by ListDeadLetters) to their original exchange and routing key and
+// removes them from the dead letter queue. It returns the replayed messages.
+func ReplayDeadLetters(conn *Conn, positions ...int) ([]DeadLetter, error) {
+	selected := map[int]struct{}{}
+	last := 0
+	for _, position := range positions {
+		if position < 1 {
+			return nil, fmt.Errorf("invalid dead letter position %v", position)
+		}
+		selected[position] = struct{}{}
+		last = max(last, position)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	err = DeclareDeadLetter(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed := []DeadLetter{}
+	for i := 1; i <= last; i++ {
+		delivery, ok, err := ch.Get(DeadLetterQueue, false)
+		if err != nil {
+			return replayed, fmt.Errorf("could not get dead letter: %w", err)
+		}
+		if !ok {
+			break
+		}
+		if _, ok := selected[i]; !ok {
+			continue
+		}
+
+		dl := newDeadLetter(i, delivery)
+		err = ch.Publish(
+			dl.OriginalExchange,
+			dl.OriginalRoutingKey,
+			false,
+			false,
+			republishing(delivery, replayHeaders(delivery.Headers)),
+		)
+		if err != nil {
+			return replayed, fmt.Errorf(
+				"could not replay dead letter %v to exchange %v with key %v:\n%w",
+				i, dl.OriginalExchange, dl.OriginalRoutingKey, err,
+			)
+		}
+		err = delivery.Ack(false)
+		if err != nil {
+			return replayed, fmt.Errorf("could not ack dead letter %v: %w", i, err)
+		}
+		replayed = append(replayed, dl)
+	}
+
+	return replayed, nil
+}
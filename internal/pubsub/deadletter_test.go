@@ -0,0 +1,123 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestNewDeadLetter(t *testing.T) {
+	death := func(queue, reason, exchange, key string) amqp.Table {
+		return amqp.Table{
+			"queue":        queue,
+			"reason":       reason,
+			"exchange":     exchange,
+			"routing-keys": []interface{}{key},
+			"count":        int64(1),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		delivery   amqp.Delivery
+		exchange   string
+		routingKey string
+		err        string
+		queues     []string
+	}{
+		{
+			name: "rejected by the queue",
+			delivery: amqp.Delivery{
+				Exchange:   DeadLetterExchange,
+				RoutingKey: "game_logs.alice",
+				Headers: amqp.Table{
+					"x-death": []interface{}{
+						death("game_logs", "rejected", "peril_topic", "game_logs.alice"),
+					},
+				},
+			},
+			exchange:   "peril_topic",
+			routingKey: "game_logs.alice",
+			queues:     []string{"game_logs"},
+		},
+		{
+			// Retries go through the default exchange, so the oldest death
+			// is in a retry queue; the headers the first retry set say where
+			// the message came from.
+			name: "retried, then rejected",
+			delivery: amqp.Delivery{
+				Exchange:   DeadLetterExchange,
+				RoutingKey: "game_logs",
+				Headers: amqp.Table{
+					HeaderAttempts:           int32(4),
+					HeaderOriginalExchange:   "peril_topic",
+					HeaderOriginalRoutingKey: "game_logs.alice",
+					"x-death": []interface{}{
+						death("game_logs", "rejected", "", "game_logs"),
+						death("game_logs.retry.5s", "expired", "", "game_logs.retry.5s"),
+						death("game_logs.retry.1s", "expired", "", "game_logs.retry.1s"),
+					},
+				},
+			},
+			exchange:   "peril_topic",
+			routingKey: "game_logs.alice",
+			queues:     []string{"game_logs", "game_logs.retry.5s", "game_logs.retry.1s"},
+		},
+		{
+			name: "dead-lettered by the subscriber",
+			delivery: amqp.Delivery{
+				Exchange:   DeadLetterExchange,
+				RoutingKey: "game_logs.alice",
+				Headers: amqp.Table{
+					HeaderError:              "could not decode",
+					HeaderQueue:              "game_logs",
+					HeaderOriginalExchange:   "peril_topic",
+					HeaderOriginalRoutingKey: "game_logs.alice",
+				},
+			},
+			exchange:   "peril_topic",
+			routingKey: "game_logs.alice",
+			err:        "could not decode",
+			queues:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dl := newDeadLetter(1, tt.delivery)
+			if dl.OriginalExchange != tt.exchange || dl.OriginalRoutingKey != tt.routingKey {
+				t.Errorf(
+					"original = %q %q, want %q %q",
+					dl.OriginalExchange, dl.OriginalRoutingKey, tt.exchange, tt.routingKey,
+				)
+			}
+			if dl.Error != tt.err {
+				t.Errorf("error = %q, want %q", dl.Error, tt.err)
+			}
+			queues := []string{}
+			for _, d := range dl.Deaths {
+				queues = append(queues, d.Queue)
+			}
+			if !reflect.DeepEqual(queues, tt.queues) {
+				t.Errorf("deaths in %v, want %v", queues, tt.queues)
+			}
+		})
+	}
+}
+
+func TestReplayHeaders(t *testing.T) {
+	headers := replayHeaders(amqp.Table{
+		HeaderSchemaVersion:      int32(2),
+		HeaderAttempts:           int32(4),
+		HeaderError:              "could not decode",
+		HeaderQueue:              "game_logs",
+		HeaderOriginalExchange:   "peril_topic",
+		HeaderOriginalRoutingKey: "game_logs.alice",
+		"x-death":                []interface{}{},
+	})
+	want := amqp.Table{HeaderSchemaVersion: int32(2)}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("replay headers = %v, want %v", headers, want)
+	}
+}
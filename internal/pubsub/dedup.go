@@ -0,0 +1,151 @@
+package pubsub
+
+import (
+	"bufio"
+	"container/list"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DedupStore remembers the IDs of messages that were handled successfully so
+// redeliveries of them can be skipped.
+type DedupStore interface {
+	Seen(id string) (bool, error)
+	MarkSeen(id string) error
+}
+
+// MemoryDedupStore is a DedupStore that keeps the most recent capacity IDs.
+type MemoryDedupStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewMemoryDedupStore(capacity int) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (s *MemoryDedupStore) Seen(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[id]
+	if ok {
+		s.order.MoveToFront(el)
+	}
+	return ok, nil
+}
+
+func (s *MemoryDedupStore) MarkSeen(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(id)
+	return nil
+}
+
+func (s *MemoryDedupStore) add(id string) {
+	if el, ok := s.entries[id]; ok {
+		s.order.MoveToFront(el)
+		return
+	}
+	s.entries[id] = s.order.PushFront(id)
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(string))
+	}
+}
+
+// ids returns the remembered IDs, oldest first.
+func (s *MemoryDedupStore) ids() []string {
+	ids := []string{}
+	for el := s.order.Back(); el != nil; el = el.Prev() {
+		ids = append(ids, el.Value.(string))
+	}
+	return ids
+}
+
+// FileDedupStore is a MemoryDedupStore that also appends every ID to a file,
+// so IDs survive a restart. The file is compacted when it is opened.
+type FileDedupStore struct {
+	*MemoryDedupStore
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileDedupStore(path string, capacity int) (*FileDedupStore, error) {
+	mem := NewMemoryDedupStore(capacity)
+
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not open dedup file: %w", err)
+	}
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			id := strings.TrimSpace(scanner.Text())
+			if id != "" {
+				mem.add(id)
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("could not read dedup file: %w", err)
+		}
+	}
+
+	// Rewrite the file with only the IDs we kept so it doesn't grow forever.
+	tmpPath := path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not compact dedup file: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	for _, id := range mem.ids() {
+		fmt.Fprintln(w, id)
+	}
+	err = w.Flush()
+	if err == nil {
+		err = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not compact dedup file: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open dedup file: %w", err)
+	}
+
+	return &FileDedupStore{
+		MemoryDedupStore: mem,
+		file:             file,
+	}, nil
+}
+
+func (s *FileDedupStore) MarkSeen(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := fmt.Fprintln(s.file, id)
+	if err != nil {
+		return fmt.Errorf("could not write dedup file: %w", err)
+	}
+	return s.MemoryDedupStore.MarkSeen(id)
+}
+
+func (s *FileDedupStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,120 @@
+package pubsub
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryDedupStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		marked   []string
+		// seen is checked before marked's last ID is added, so a hit there
+		// keeps the ID from being evicted.
+		seen []string
+		want []string
+	}{
+		{
+			name:     "keeps every ID under capacity",
+			capacity: 3,
+			marked:   []string{"a", "b", "c"},
+			want:     []string{"a", "b", "c"},
+		},
+		{
+			name:     "evicts the oldest ID",
+			capacity: 2,
+			marked:   []string{"a", "b", "c"},
+			want:     []string{"b", "c"},
+		},
+		{
+			name:     "a seen ID is no longer the oldest",
+			capacity: 2,
+			marked:   []string{"a", "b", "c"},
+			seen:     []string{"a"},
+			want:     []string{"a", "c"},
+		},
+		{
+			name:     "marking an ID twice keeps one copy",
+			capacity: 2,
+			marked:   []string{"a", "b", "a", "c"},
+			want:     []string{"a", "c"},
+		},
+		{
+			name:     "capacity is at least one",
+			capacity: 0,
+			marked:   []string{"a", "b"},
+			want:     []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryDedupStore(tt.capacity)
+			last := len(tt.marked) - 1
+			for _, id := range tt.marked[:last] {
+				store.MarkSeen(id)
+			}
+			for _, id := range tt.seen {
+				if ok, _ := store.Seen(id); !ok {
+					t.Errorf("%s should have been seen", id)
+				}
+			}
+			store.MarkSeen(tt.marked[last])
+
+			if ids := store.ids(); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("ids = %v, want %v", ids, tt.want)
+			}
+			for _, id := range tt.want {
+				if ok, _ := store.Seen(id); !ok {
+					t.Errorf("%s should have been seen", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFileDedupStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup")
+
+	store, err := NewFileDedupStore(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := store.MarkSeen(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a\nb\nc\n" {
+		t.Errorf("file = %q, want every ID appended", data)
+	}
+
+	store, err = NewFileDedupStore(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if ok, _ := store.Seen("a"); ok {
+		t.Error("a should have been evicted")
+	}
+	if ok, _ := store.Seen("c"); !ok {
+		t.Error("c should have survived the restart")
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "b\nc\n" {
+		t.Errorf("file = %q, want it compacted to the kept IDs", data)
+	}
+}
@@ -0,0 +1,152 @@
+package pubsub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const HeaderSchemaVersion = "x-schema-version"
+
+// DefaultSchemaVersion is stamped on messages published without an explicit
+// schema version, and assumed for deliveries that carry none.
+const DefaultSchemaVersion = 1
+
+var appID = filepath.Base(os.Args[0])
+
+// SetAppID sets the AppId stamped on everything this process publishes.
+func SetAppID(id string) {
+	appID = id
+}
+
+// Delivery is the envelope a message arrived in, passed to handlers next to
+// the decoded payload.
+type Delivery struct {
+	MessageID     string
+	CorrelationID string
+	AppID         string
+	Timestamp     time.Time
+	SchemaVersion int
+	Exchange      string
+	RoutingKey    string
+	ContentType   ContentType
+	Redelivered   bool
+	Headers       amqp.Table
+}
+
+func newDelivery(d amqp.Delivery) Delivery {
+	return Delivery{
+		MessageID:     d.MessageId,
+		CorrelationID: d.CorrelationId,
+		AppID:         d.AppId,
+		Timestamp:     d.Timestamp,
+		SchemaVersion: schemaVersion(d.Headers),
+		Exchange:      d.Exchange,
+		RoutingKey:    d.RoutingKey,
+		ContentType:   ContentType(d.ContentType),
+		Redelivered:   d.Redelivered,
+		Headers:       d.Headers,
+	}
+}
+
+func schemaVersion(headers amqp.Table) int {
+	switch v := headers[HeaderSchemaVersion].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return DefaultSchemaVersion
+}
+
+type publishOptions struct {
+	correlationID string
+	schemaVersion int
+	headers       amqp.Table
+}
+
+type PublishOption func(*publishOptions)
+
+// WithCorrelationID ties a message to the one that caused it, usually the
+// MessageID of the delivery being handled.
+func WithCorrelationID(id string) PublishOption {
+	return func(o *publishOptions) {
+		o.correlationID = id
+	}
+}
+
+func WithSchemaVersion(version int) PublishOption {
+	return func(o *publishOptions) {
+		o.schemaVersion = version
+	}
+}
+
+func WithHeader(key string, value any) PublishOption {
+	return func(o *publishOptions) {
+		o.headers[key] = value
+	}
+}
+
+// newPublishing stamps the standard envelope on body.
+func newPublishing(contentType ContentType, body []byte, opts []PublishOption) (amqp.Publishing, error) {
+	options := publishOptions{
+		schemaVersion: DefaultSchemaVersion,
+		headers:       amqp.Table{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	id, err := newMessageID()
+	if err != nil {
+		return amqp.Publishing{}, err
+	}
+
+	headers := options.headers
+	headers[HeaderSchemaVersion] = int32(options.schemaVersion)
+
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   string(contentType),
+		MessageId:     id,
+		CorrelationId: options.correlationID,
+		AppId:         appID,
+		Timestamp:     time.Now().UTC(),
+		Body:          body,
+	}, nil
+}
+
+func newMessageID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("could not generate message id: %w", err)
+	}
+	// Format as a version 4 UUID.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+// republishing copies d, envelope included, into a Publishing with headers
+// replacing the original ones.
+func republishing(d amqp.Delivery, headers amqp.Table) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.ContentType,
+		DeliveryMode:  d.DeliveryMode,
+		MessageId:     d.MessageId,
+		CorrelationId: d.CorrelationId,
+		AppId:         d.AppId,
+		Timestamp:     d.Timestamp,
+		Body:          d.Body,
+	}
+}
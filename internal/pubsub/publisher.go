@@ -0,0 +1,93 @@
+package pubsub
+
+import (
+	"errors"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publisher publishes over a pool of channels. AMQP channels must not be
+// published to from several goroutines at once, so each publish borrows a
+// channel for its own use. Channels closed by an error or a reconnect are
+// replaced on the next publish. A Publisher is safe for concurrent use.
+type Publisher struct {
+	conn  *Conn
+	slots chan struct{}
+	idle  chan *amqp.Channel
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewPublisher returns a Publisher that keeps at most size channels open.
+func NewPublisher(conn *Conn, size int) *Publisher {
+	size = max(size, 1)
+	return &Publisher{
+		conn:  conn,
+		slots: make(chan struct{}, size),
+		idle:  make(chan *amqp.Channel, size),
+	}
+}
+
+func (p *Publisher) Publish(
+	exchange,
+	key string,
+	mandatory,
+	immediate bool,
+	msg amqp.Publishing,
+) error {
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	var ch *amqp.Channel
+	select {
+	case ch = <-p.idle:
+	default:
+	}
+
+	// A pooled channel may have died since it was last used. Give the
+	// publish one more go on a fresh channel before giving up.
+	for attempt := 0; ; attempt++ {
+		if ch == nil || ch.IsClosed() {
+			var err error
+			ch, err = p.conn.openChannel()
+			if err != nil {
+				return err
+			}
+		}
+
+		err := ch.Publish(exchange, key, mandatory, immediate, msg)
+		if err == nil {
+			p.idle <- ch
+			return nil
+		}
+		ch.Close()
+		ch = nil
+		if !errors.Is(err, amqp.ErrClosed) || attempt > 0 {
+			return err
+		}
+	}
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	for {
+		select {
+		case ch := <-p.idle:
+			ch.Close()
+		default:
+			return nil
+		}
+	}
+}
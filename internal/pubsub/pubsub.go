@@ -1,11 +1,7 @@
 package pubsub
 
 import (
-	"bytes"
-	"encoding/gob"
-	"encoding/json"
 	"fmt"
-	"log"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -18,8 +14,16 @@ const (
 	NackDiscard
 )
 
+// QueueArgs are the arguments every subscriber queue is declared with.
+// Anything that declares the same queues ahead of time has to match them.
+func QueueArgs() amqp.Table {
+	return amqp.Table{
+		"x-dead-letter-exchange": DeadLetterExchange,
+	}
+}
+
 func DeclareAndBind(
-	conn *amqp.Connection,
+	conn *Conn,
 	exchange,
 	queueName,
 	key string,
@@ -27,186 +31,80 @@ func DeclareAndBind(
 ) (*amqp.Channel, amqp.Queue, error) {
 	ch, err := conn.Channel()
 	if err != nil {
-		return nil, amqp.Queue{}, fmt.Errorf("could not create channel: %w", err)
-	}
-
-	queue, err := ch.QueueDeclare(
-		queueName,
-		durable,
-		!durable,
-		!durable,
-		false,
-		amqp.Table{
-			"x-dead-letter-exchange": "peril_dlx",
-		},
-	)
-	if err != nil {
-		return nil, amqp.Queue{}, fmt.Errorf("could not create queue: %w", err)
+		return nil, amqp.Queue{}, err
 	}
-	err = ch.QueueBind(queueName, key, exchange, false, nil)
+	queue, err := declareAndBind(ch, exchange, queueName, key, durable)
 	if err != nil {
-		return nil, amqp.Queue{}, fmt.Errorf("could not bind queue: %w", err)
+		ch.Close()
+		return nil, amqp.Queue{}, err
 	}
-
 	return ch, queue, nil
 }
 
-func subscribe[T any](
-	conn *amqp.Connection,
+// declareAndBind declares queueName on ch and binds it. The caller closes ch
+// if it fails.
+func declareAndBind(
+	ch *amqp.Channel,
 	exchange,
 	queueName,
 	key string,
 	durable bool,
-	handler func(T) AckType,
-	unmarshaller func([]byte) (T, error),
-) error {
-	ch, _, err := DeclareAndBind(
-		conn,
-		exchange,
-		queueName,
-		key,
-		durable,
-	)
+) (amqp.Queue, error) {
+	err := DeclareDeadLetter(ch)
 	if err != nil {
-		return fmt.Errorf("could not declare and bind queue: %w", err)
+		return amqp.Queue{}, err
 	}
 
-	deliveryCh, err := ch.Consume(
+	queue, err := ch.QueueDeclare(
 		queueName,
-		"",
-		false,
-		false,
-		false,
+		durable,
+		!durable,
+		!durable,
 		false,
-		nil,
+		QueueArgs(),
 	)
 	if err != nil {
-		return fmt.Errorf("could not consume queue: %w", err)
+		return amqp.Queue{}, fmt.Errorf("could not create queue: %w", err)
 	}
-
-	go func() {
-		defer ch.Close()
-		for delivery := range deliveryCh {
-			var data T
-			data, err := unmarshaller(delivery.Body)
-			if err != nil {
-				log.Printf("could not unmarshal delivery: %v", err)
-			}
-			ackType := handler(data)
-			switch ackType {
-			case Ack:
-				delivery.Ack(false)
-			case NackRequeue:
-				delivery.Nack(false, true)
-			case NackDiscard:
-				delivery.Nack(false, false)
-			}
-		}
-	}()
-
-	return nil
-}
-
-func SubscribeJSON[T any](
-	conn *amqp.Connection,
-	exchange,
-	queueName,
-	key string,
-	durable bool,
-	handler func(T) AckType,
-) error {
-	unmarshaller := func(deliveryBody []byte) (T, error) {
-		var data T
-		err := json.Unmarshal(deliveryBody, &data)
-		if err != nil {
-			return data, fmt.Errorf("could not unmarshal delivery: %v", err)
-		}
-		return data, nil
+	err = ch.QueueBind(queueName, key, exchange, false, nil)
+	if err != nil {
+		return amqp.Queue{}, fmt.Errorf("could not bind queue: %w", err)
 	}
+	return queue, nil
+}
 
-	return subscribe(
-		conn,
-		exchange,
-		queueName,
-		key,
-		durable,
-		handler,
-		unmarshaller,
-	)
+// Channel is the publishing half of *amqp.Channel. A ConfirmingPublisher can
+// be used wherever a plain channel is.
+type Channel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
 }
 
-func SubscribeGob[T any](conn *amqp.Connection,
+// Publish encodes val with the codec registered for contentType and stamps it
+// with a fresh message ID, timestamp, AppId and schema version.
+func Publish[T any](
+	ch Channel,
+	contentType ContentType,
 	exchange,
-	queueName,
 	key string,
-	durable bool,
-	handler func(T) AckType,
+	val T,
+	opts ...PublishOption,
 ) error {
-	unmarshaller := func(deliveryBody []byte) (T, error) {
-		var data T
-		decoder := gob.NewDecoder(bytes.NewBuffer(deliveryBody))
-		err := decoder.Decode(&data)
-		if err != nil {
-			return data, fmt.Errorf("could not unmarshal delivery: %v", err)
-		}
-		return data, nil
-	}
-
-	return subscribe(
-		conn,
-		exchange,
-		queueName,
-		key,
-		durable,
-		handler,
-		unmarshaller,
-	)
-}
-
-func PublishJSON[T any](ch *amqp.Channel, exchange, key string, val T) error {
-	jsonData, err := json.Marshal(val)
+	codec, err := LookupCodec(contentType)
 	if err != nil {
-		return fmt.Errorf("could not marshal value %v:\n%w", val, err)
+		return err
 	}
 
-	err = ch.Publish(
-		exchange,
-		key,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        jsonData,
-		},
-	)
+	body, err := codec.Marshal(val)
 	if err != nil {
-		return fmt.Errorf(
-			"could not publish to exchange %v with key %v:\n%w",
-			exchange, key, err,
-		)
+		return fmt.Errorf("could not encode value %v as %v:\n%w", val, contentType, err)
 	}
 
-	return nil
-}
-
-func PublishGob[T any](ch *amqp.Channel, exchange, key string, val T) error {
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
-	err := encoder.Encode(val)
+	msg, err := newPublishing(contentType, body, opts)
 	if err != nil {
-		return fmt.Errorf("could not encode value: %w", err)
+		return err
 	}
 
-	err = ch.Publish(
-		exchange,
-		key,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/gob",
-			Body:        buffer.Bytes(),
-		},
-	)
+	err = ch.Publish(exchange, key, false, false, msg)
 	if err != nil {
 		return fmt.Errorf(
 			"could not publish to exchange %v with key %v:\n%w",
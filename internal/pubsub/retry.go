@@ -0,0 +1,124 @@
+package pubsub
+
+import (
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// HeaderAttempts counts how many times a delivery has been sent back for
+// another try.
+const HeaderAttempts = "x-peril-attempts"
+
+// RetryPolicy controls what happens when a handler returns NackRequeue.
+// Instead of going straight back to the head of the queue, the delivery waits
+// in a retry queue for Delays[attempt-1] (the last delay is reused once they
+// run out). After MaxAttempts tries it is dead-lettered.
+type RetryPolicy struct {
+	Delays      []time.Duration
+	MaxAttempts int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Delays:      []time.Duration{1 * time.Second, 5 * time.Second, 30 * time.Second},
+	MaxAttempts: 5,
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	return p.Delays[min(attempt, len(p.Delays))-1]
+}
+
+func retryQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", queueName, delay)
+}
+
+// declareRetryQueues declares one queue per delay. Messages expire out of
+// them back into queueName through the default exchange.
+func declareRetryQueues(
+	ch *amqp.Channel,
+	queueName string,
+	durable bool,
+	policy RetryPolicy,
+) error {
+	for _, delay := range policy.Delays {
+		args := amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		}
+		if !durable {
+			// Nobody consumes a retry queue, so auto-delete never kicks in.
+			// Let the broker drop it once the subscriber has been gone a while.
+			args["x-expires"] = (delay + time.Minute).Milliseconds()
+		}
+
+		_, err := ch.QueueDeclare(
+			retryQueueName(queueName, delay),
+			durable,
+			false,
+			false,
+			false,
+			args,
+		)
+		if err != nil {
+			return fmt.Errorf("could not declare retry queue for %v: %w", delay, err)
+		}
+	}
+	return nil
+}
+
+func attempts(headers amqp.Table) int {
+	switch v := headers[HeaderAttempts].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// retryHeaders copies delivery's headers for its next attempt. Retries come
+// back through the default exchange, so the first one records where the
+// delivery was originally published, for dead lettering it once it runs out
+// of attempts.
+func retryHeaders(delivery amqp.Delivery, attempt int) amqp.Table {
+	headers := amqp.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+	headers[HeaderAttempts] = int32(attempt)
+	if _, ok := headers[HeaderOriginalExchange]; !ok {
+		headers[HeaderOriginalExchange] = delivery.Exchange
+		headers[HeaderOriginalRoutingKey] = delivery.RoutingKey
+	}
+	return headers
+}
+
+// retry sends delivery to the retry queue for its next attempt, or
+// dead-letters it once it has used up its attempts.
+func retry(
+	ch *amqp.Channel,
+	queueName string,
+	delivery amqp.Delivery,
+	policy RetryPolicy,
+) error {
+	attempt := attempts(delivery.Headers) + 1
+	if attempt >= policy.MaxAttempts {
+		return delivery.Nack(false, false)
+	}
+
+	err := ch.Publish(
+		"",
+		retryQueueName(queueName, policy.delay(attempt)),
+		false,
+		false,
+		republishing(delivery, retryHeaders(delivery, attempt)),
+	)
+	if err != nil {
+		return fmt.Errorf("could not publish to retry queue: %w", err)
+	}
+	return delivery.Ack(false)
+}
@@ -0,0 +1,110 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{
+		Delays:      []time.Duration{time.Second, 5 * time.Second},
+		MaxAttempts: 5,
+	}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 5 * time.Second},
+		{attempt: 3, want: 5 * time.Second},
+		{attempt: 10, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.delay(tt.attempt); got != tt.want {
+			t.Errorf("delay(%v) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestAttempts(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers amqp.Table
+		want    int
+	}{
+		{name: "int32", headers: amqp.Table{HeaderAttempts: int32(2)}, want: 2},
+		{name: "int64", headers: amqp.Table{HeaderAttempts: int64(3)}, want: 3},
+		{name: "int", headers: amqp.Table{HeaderAttempts: 4}, want: 4},
+		{name: "missing", headers: amqp.Table{}, want: 0},
+		{name: "no headers", headers: nil, want: 0},
+		{name: "not a number", headers: amqp.Table{HeaderAttempts: "2"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attempts(tt.headers); got != tt.want {
+				t.Errorf("attempts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		delivery   amqp.Delivery
+		attempt    int
+		exchange   string
+		routingKey string
+	}{
+		{
+			name: "first retry records where it was published",
+			delivery: amqp.Delivery{
+				Exchange:   "peril_topic",
+				RoutingKey: "game_logs.alice",
+				Headers:    amqp.Table{HeaderSchemaVersion: int32(1)},
+			},
+			attempt:    1,
+			exchange:   "peril_topic",
+			routingKey: "game_logs.alice",
+		},
+		{
+			name: "later retries keep it",
+			delivery: amqp.Delivery{
+				Exchange:   "",
+				RoutingKey: "game_logs",
+				Headers: amqp.Table{
+					HeaderAttempts:           int32(1),
+					HeaderOriginalExchange:   "peril_topic",
+					HeaderOriginalRoutingKey: "game_logs.alice",
+				},
+			},
+			attempt:    2,
+			exchange:   "peril_topic",
+			routingKey: "game_logs.alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := retryHeaders(tt.delivery, tt.attempt)
+			if got := attempts(headers); got != tt.attempt {
+				t.Errorf("attempts = %v, want %v", got, tt.attempt)
+			}
+			if got := headers[HeaderOriginalExchange]; got != tt.exchange {
+				t.Errorf("original exchange = %q, want %q", got, tt.exchange)
+			}
+			if got := headers[HeaderOriginalRoutingKey]; got != tt.routingKey {
+				t.Errorf("original routing key = %q, want %q", got, tt.routingKey)
+			}
+			for k, v := range tt.delivery.Headers {
+				if _, ok := headers[k]; !ok {
+					t.Errorf("header %s = %v was dropped", k, v)
+				}
+			}
+		})
+	}
+}
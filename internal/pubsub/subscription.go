@@ -0,0 +1,395 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Subscription is a handle on a running consumer. Close stops it.
+type Subscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	decodeFailures       atomic.Int64
+	duplicatesSuppressed atomic.Int64
+}
+
+// DecodeFailures reports how many deliveries were dead-lettered because they
+// could not be decoded.
+func (s *Subscription) DecodeFailures() int64 {
+	return s.decodeFailures.Load()
+}
+
+// DuplicatesSuppressed reports how many deliveries were skipped because the
+// dedup store had already seen their message ID.
+func (s *Subscription) DuplicatesSuppressed() int64 {
+	return s.duplicatesSuppressed.Load()
+}
+
+// DecodeError is passed to the error handler when a delivery cannot be
+// decoded. Such deliveries never reach the subscription's handler.
+type DecodeError struct {
+	Queue       string
+	RoutingKey  string
+	ContentType string
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf(
+		"could not decode %s delivery from %s on %s: %v",
+		e.ContentType, e.RoutingKey, e.Queue, e.Err,
+	)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Close stops consuming, waits for the handler that is currently running (if
+// any) to finish and ack, and then closes the channel. Deliveries that were
+// prefetched but not handled are requeued by the broker.
+func (s *Subscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+type subscribeOptions struct {
+	prefetch         int
+	workers          int
+	errorHandler     func(error)
+	maxSchemaVersion int
+	upcast           Upcaster
+	dedup            DedupStore
+	retry            *RetryPolicy
+}
+
+// Upcaster rewrites the body of a delivery published with a newer schema
+// version into one this build can decode.
+type Upcaster func(d Delivery, body []byte) ([]byte, error)
+
+type SubscribeOption func(*subscribeOptions)
+
+// WithPrefetch limits how many unacked deliveries the broker pushes to the
+// subscription at once. Zero, the default, means no limit.
+func WithPrefetch(count int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.prefetch = count
+	}
+}
+
+// WithWorkers runs the handler on n goroutines. Each delivery is still acked
+// or nacked individually by the worker that handled it.
+func WithWorkers(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.workers = n
+	}
+}
+
+// WithErrorHandler is called with a *DecodeError for every delivery that is
+// dead-lettered because it could not be decoded.
+func WithErrorHandler(handler func(error)) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.errorHandler = handler
+	}
+}
+
+// WithMaxSchemaVersion makes the subscription reject deliveries whose schema
+// version is newer than max. If upcast is not nil those deliveries are passed
+// through it instead, and only rejected if it fails.
+func WithMaxSchemaVersion(max int, upcast Upcaster) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.maxSchemaVersion = max
+		o.upcast = upcast
+	}
+}
+
+// WithDedup skips deliveries whose message ID is already in store, and adds
+// the ID of every delivery the handler acks.
+func WithDedup(store DedupStore) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.dedup = store
+	}
+}
+
+// WithRetry sends deliveries the handler returns NackRequeue for through
+// delayed retry queues instead of requeueing them immediately.
+func WithRetry(policy RetryPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.retry = &policy
+	}
+}
+
+func newSubscribeOptions(opts []SubscribeOption) subscribeOptions {
+	options := subscribeOptions{
+		prefetch: 0,
+		workers:  1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.workers = max(options.workers, 1)
+	if options.retry != nil && len(options.retry.Delays) == 0 {
+		options.retry.Delays = DefaultRetryPolicy.Delays
+	}
+	return options
+}
+
+// Subscribe consumes queueName and calls handler with each delivery decoded
+// by the codec registered for the delivery's content type, along with the
+// delivery's envelope.
+func Subscribe[T any](
+	conn *Conn,
+	exchange,
+	queueName,
+	key string,
+	durable bool,
+	handler func(T, Delivery) AckType,
+	opts ...SubscribeOption,
+) (*Subscription, error) {
+	options := newSubscribeOptions(opts)
+
+	// consume opens a channel with open and starts consuming on it. The first
+	// subscription waits for the connection, but resubscribing mustn't:
+	// waiting there would keep Close from stopping the subscription while
+	// the broker is down.
+	consume := func(open func() (*amqp.Channel, error)) (*amqp.Channel, <-chan amqp.Delivery, error) {
+		ch, err := open()
+		if err != nil {
+			return nil, nil, err
+		}
+		_, err = declareAndBind(ch, exchange, queueName, key, durable)
+		if err != nil {
+			ch.Close()
+			return nil, nil, fmt.Errorf("could not declare and bind queue: %w", err)
+		}
+
+		if options.retry != nil {
+			err = declareRetryQueues(ch, queueName, durable, *options.retry)
+			if err != nil {
+				ch.Close()
+				return nil, nil, err
+			}
+		}
+
+		if options.prefetch > 0 {
+			err = ch.Qos(options.prefetch, 0, false)
+			if err != nil {
+				ch.Close()
+				return nil, nil, fmt.Errorf("could not set prefetch count: %w", err)
+			}
+		}
+
+		deliveryCh, err := ch.Consume(
+			queueName,
+			"",
+			false,
+			false,
+			false,
+			false,
+			nil,
+		)
+		if err != nil {
+			ch.Close()
+			return nil, nil, fmt.Errorf("could not consume queue: %w", err)
+		}
+		return ch, deliveryCh, nil
+	}
+
+	ch, deliveryCh, err := consume(conn.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &Subscription{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	handle := func(delivery amqp.Delivery) {
+		meta := newDelivery(delivery)
+
+		dedup := options.dedup != nil && meta.MessageID != ""
+		if dedup {
+			seen, err := options.dedup.Seen(meta.MessageID)
+			if err != nil {
+				log.Printf("could not check dedup store: %v", err)
+			}
+			if seen {
+				sub.duplicatesSuppressed.Add(1)
+				delivery.Ack(false)
+				return
+			}
+		}
+
+		data, err := decode[T](meta, delivery.Body, options)
+		if err != nil {
+			decodeErr := &DecodeError{
+				Queue:       queueName,
+				RoutingKey:  delivery.RoutingKey,
+				ContentType: delivery.ContentType,
+				Err:         err,
+			}
+			sub.decodeFailures.Add(1)
+			log.Println(decodeErr)
+			if options.errorHandler != nil {
+				options.errorHandler(decodeErr)
+			}
+
+			err = publishDeadLetter(ch, queueName, delivery, decodeErr)
+			if err != nil {
+				// Fall back to the queue's own dead lettering, which loses
+				// the error header but still keeps the message around.
+				log.Printf("could not dead-letter delivery: %v", err)
+				delivery.Nack(false, false)
+				return
+			}
+			delivery.Ack(false)
+			return
+		}
+
+		ackType := handler(data, meta)
+		switch ackType {
+		case Ack:
+			if dedup {
+				err := options.dedup.MarkSeen(meta.MessageID)
+				if err != nil {
+					log.Printf("could not update dedup store: %v", err)
+				}
+			}
+			delivery.Ack(false)
+		case NackRequeue:
+			if options.retry == nil {
+				delivery.Nack(false, true)
+				return
+			}
+			err := retry(ch, queueName, delivery, *options.retry)
+			if err != nil {
+				log.Printf("could not schedule retry, requeueing: %v", err)
+				delivery.Nack(false, true)
+			}
+		case NackDiscard:
+			delivery.Nack(false, false)
+		}
+	}
+
+	go func() {
+		defer close(sub.done)
+		for {
+			consumeDeliveries(ctx, deliveryCh, options.workers, handle)
+			ch.Close()
+			if ctx.Err() != nil {
+				return
+			}
+
+			// The delivery channel closes when the connection or channel
+			// drops. Keep trying to resubscribe until we are stopped.
+			delay := minReconnectDelay
+			for {
+				ch, deliveryCh, err = consume(conn.openChannel)
+				if err == nil {
+					break
+				}
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				// While the connection is down, poll for it at the shortest
+				// delay, so we resubscribe soon after it is back.
+				wait := minReconnectDelay
+				if !errors.Is(err, ErrNotConnected) {
+					log.Printf("could not resubscribe to %v, retrying in %v: %v", queueName, delay, err)
+					wait = delay
+					delay = nextReconnectDelay(delay)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// consumeDeliveries hands deliveries to workers until the delivery channel
+// closes or ctx is cancelled, and returns once every worker is idle.
+func consumeDeliveries(
+	ctx context.Context,
+	deliveryCh <-chan amqp.Delivery,
+	workers int,
+	handle func(amqp.Delivery),
+) {
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery, ok := <-deliveryCh:
+					if !ok {
+						return
+					}
+					handle(delivery)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// RawMessage can be subscribed to in order to receive message bodies as they
+// arrived, whatever their content type.
+type RawMessage []byte
+
+// Decode decodes body with the codec registered for contentType.
+func Decode[T any](contentType ContentType, body []byte) (T, error) {
+	var data T
+	if raw, ok := any(&data).(*RawMessage); ok {
+		*raw = body
+		return data, nil
+	}
+
+	codec, err := LookupCodec(contentType)
+	if err != nil {
+		return data, err
+	}
+	err = codec.Unmarshal(body, &data)
+	if err != nil {
+		return data, fmt.Errorf("could not unmarshal delivery: %w", err)
+	}
+	return data, nil
+}
+
+func decode[T any](meta Delivery, body []byte, options subscribeOptions) (T, error) {
+	var data T
+
+	if options.maxSchemaVersion > 0 && meta.SchemaVersion > options.maxSchemaVersion {
+		if options.upcast == nil {
+			return data, fmt.Errorf(
+				"unsupported schema version %v, newest supported is %v",
+				meta.SchemaVersion, options.maxSchemaVersion,
+			)
+		}
+		upcast, err := options.upcast(meta, body)
+		if err != nil {
+			return data, fmt.Errorf("could not upcast schema version %v: %w", meta.SchemaVersion, err)
+		}
+		body = upcast
+	}
+
+	return Decode[T](meta.ContentType, body)
+}
@@ -6,8 +6,23 @@ type PlayingState struct {
 	IsPaused bool
 }
 
+type ScenarioRequest struct {
+	Username string
+}
+
 type GameLog struct {
 	CurrentTime time.Time
 	Message     string
 	Username    string
+	Battles     []Battle
+}
+
+// Battle is the result of a war in one location. Winner is empty for a draw
+// and Casualties holds the unit keys of every unit killed.
+type Battle struct {
+	Location      string
+	Winner        string
+	AttackerPower int
+	DefenderPower int
+	Casualties    []string
 }
@@ -0,0 +1,70 @@
+package routing
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/perilpb"
+)
+
+func (ps PlayingState) MarshalProto() ([]byte, error) {
+	return proto.Marshal(&perilpb.PlayingState{
+		IsPaused: ps.IsPaused,
+	})
+}
+
+func (ps *PlayingState) UnmarshalProto(data []byte) error {
+	pb := &perilpb.PlayingState{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	*ps = PlayingState{
+		IsPaused: pb.GetIsPaused(),
+	}
+	return nil
+}
+
+func (gl GameLog) MarshalProto() ([]byte, error) {
+	battles := []*perilpb.Battle{}
+	for _, b := range gl.Battles {
+		battles = append(battles, &perilpb.Battle{
+			Location:      b.Location,
+			Winner:        b.Winner,
+			AttackerPower: int64(b.AttackerPower),
+			DefenderPower: int64(b.DefenderPower),
+			Casualties:    b.Casualties,
+		})
+	}
+	return proto.Marshal(&perilpb.GameLog{
+		CurrentTime: timestamppb.New(gl.CurrentTime),
+		Message:     gl.Message,
+		Username:    gl.Username,
+		Battles:     battles,
+	})
+}
+
+func (gl *GameLog) UnmarshalProto(data []byte) error {
+	pb := &perilpb.GameLog{}
+	err := proto.Unmarshal(data, pb)
+	if err != nil {
+		return err
+	}
+	battles := []Battle{}
+	for _, b := range pb.GetBattles() {
+		battles = append(battles, Battle{
+			Location:      b.GetLocation(),
+			Winner:        b.GetWinner(),
+			AttackerPower: int(b.GetAttackerPower()),
+			DefenderPower: int(b.GetDefenderPower()),
+			Casualties:    b.GetCasualties(),
+		})
+	}
+	*gl = GameLog{
+		CurrentTime: pb.GetCurrentTime().AsTime(),
+		Message:     pb.GetMessage(),
+		Username:    pb.GetUsername(),
+		Battles:     battles,
+	}
+	return nil
+}
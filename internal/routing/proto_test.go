@@ -0,0 +1,34 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGameLogProtoRoundTrip(t *testing.T) {
+	gl := GameLog{
+		CurrentTime: time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC),
+		Message:     "alice won a war against bob",
+		Username:    "alice",
+		Battles: []Battle{{
+			Location:      "europe",
+			Winner:        "alice",
+			AttackerPower: 5,
+			DefenderPower: 1,
+			Casualties:    []string{"bob_1"},
+		}},
+	}
+
+	data, err := gl.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := GameLog{}
+	if err := got.UnmarshalProto(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, gl) {
+		t.Errorf("decoded %+v, want %+v", got, gl)
+	}
+}
@@ -3,10 +3,32 @@ package routing
 const (
 	ArmyMovesPrefix = "army_moves"
 
+	AcceptedMovesPrefix = "accepted_moves"
+
+	SpawnsPrefix = "spawns"
+
+	MoveRejectionsPrefix = "move_rejections"
+
+	// WorldPrefix names the queues the server keeps the world up to date
+	// from. They are exclusive, so only one server can own the world.
+	WorldPrefix = "world"
+
 	WarRecognitionsPrefix = "war"
 
 	PauseKey = "pause"
 
+	// TickKey is the game clock: every tick, units in transit move along.
+	TickKey = "tick"
+
+	// The server broadcasts the scenario it is running with ScenarioKey, and
+	// again whenever a client asks with ScenarioRequestsKey.
+	ScenarioKey         = "scenario"
+	ScenarioRequestsKey = "scenario_requests"
+
+	// RosterPrefix is followed by the username the server is telling which
+	// units it has, in answer to a scenario request.
+	RosterPrefix = "roster"
+
 	GameLogSlug = "game_logs"
 )
 
@@ -0,0 +1,110 @@
+// Package topology describes the exchanges, queues and bindings Peril needs
+// on the broker, so they can be declared from code instead of by hand.
+package topology
+
+import (
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/pubsub"
+	"github.com/bootdotdev/learn-pub-sub-starter/internal/routing"
+)
+
+type Exchange struct {
+	Name    string
+	Kind    string
+	Durable bool
+}
+
+type Queue struct {
+	Name    string
+	Durable bool
+	Args    amqp.Table
+}
+
+type Binding struct {
+	Queue    string
+	Exchange string
+	Key      string
+}
+
+type Topology struct {
+	Exchanges []Exchange
+	Queues    []Queue
+	Bindings  []Binding
+}
+
+// Peril is the shared topology. Per-player queues are left out: clients
+// declare those themselves when they subscribe.
+func Peril() Topology {
+	return Topology{
+		Exchanges: []Exchange{
+			{Name: routing.ExchangePerilDirect, Kind: amqp.ExchangeDirect, Durable: true},
+			{Name: routing.ExchangePerilTopic, Kind: amqp.ExchangeTopic, Durable: true},
+			{Name: pubsub.DeadLetterExchange, Kind: amqp.ExchangeFanout, Durable: true},
+		},
+		Queues: []Queue{
+			{Name: pubsub.DeadLetterQueue, Durable: true},
+			{Name: routing.GameLogSlug, Durable: true, Args: pubsub.QueueArgs()},
+		},
+		Bindings: []Binding{
+			{Queue: pubsub.DeadLetterQueue, Exchange: pubsub.DeadLetterExchange, Key: ""},
+			{Queue: routing.GameLogSlug, Exchange: routing.ExchangePerilTopic, Key: routing.GameLogSlug + ".*"},
+		},
+	}
+}
+
+// Apply declares everything in t. Declarations are idempotent, so it is safe
+// to run on every startup.
+func (t Topology) Apply(conn *pubsub.Conn) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for _, ex := range t.Exchanges {
+		err := ch.ExchangeDeclare(ex.Name, ex.Kind, ex.Durable, !ex.Durable, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("could not declare exchange %v: %w", ex.Name, err)
+		}
+	}
+	for _, q := range t.Queues {
+		_, err := ch.QueueDeclare(q.Name, q.Durable, !q.Durable, false, false, q.Args)
+		if err != nil {
+			return fmt.Errorf("could not declare queue %v: %w", q.Name, err)
+		}
+	}
+	for _, b := range t.Bindings {
+		err := ch.QueueBind(b.Queue, b.Key, b.Exchange, false, nil)
+		if err != nil {
+			return fmt.Errorf(
+				"could not bind queue %v to exchange %v with key %v: %w",
+				b.Queue, b.Exchange, b.Key, err,
+			)
+		}
+	}
+
+	return nil
+}
+
+type ChangeKind string
+
+const (
+	ChangeMissing  ChangeKind = "missing"
+	ChangeConflict ChangeKind = "conflict"
+)
+
+type Change struct {
+	Kind   ChangeKind
+	Object string
+	Detail string
+}
+
+func (c Change) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s: %s", c.Kind, c.Object)
+	}
+	return fmt.Sprintf("%s: %s (%s)", c.Kind, c.Object, c.Detail)
+}
@@ -23,9 +23,10 @@ cleanup() {
 # Setup trap for SIGINT
 trap 'cleanup' SIGINT
 
-# Start the specified number of instances of the program in the background
+# Start the specified number of instances of the program in the background.
+# They only consume game logs: run one ./cmd/server on its own to own the world.
 for (( i=0; i<num_instances; i++ )); do
-  go run ./cmd/server &
+  go run ./cmd/server -authority=false &
   pids+=($!)
 done
 